@@ -0,0 +1,103 @@
+package go_in_memory_cache
+
+import "hash/maphash"
+
+// admission is a TinyLFU filter: a doorkeeper bloom filter absorbs the
+// first sighting of each key so that one-hit wonders never reach the
+// frequency sketch, and a new key is only admitted into a full shard if it
+// has been seen more often than the entry it would evict.
+type admission struct {
+	door   doorkeeper
+	sketch *frequencySketch
+}
+
+func newAdmission(width int) *admission {
+	a := &admission{sketch: newFrequencySketch(width)}
+	a.door.init(width)
+	return a
+}
+
+func (a *admission) record(key string) {
+	if a.door.add(key) {
+		return
+	}
+	before := a.sketch.additions
+	a.sketch.increment(key)
+	if a.sketch.additions < before {
+		// The sketch just aged its counters; start the doorkeeper over too.
+		a.door.clear()
+	}
+}
+
+func (a *admission) estimate(key string) int {
+	n := int(a.sketch.estimate(key))
+	if a.door.contains(key) {
+		n++
+	}
+	return n
+}
+
+// doorkeeper is a bloom filter with two hash functions.
+type doorkeeper struct {
+	seeds [2]maphash.Seed
+	bits  []uint64
+	mask  uint64
+}
+
+func (d *doorkeeper) init(width int) {
+	size := 64
+	for size < width*8 {
+		size <<= 1
+	}
+	d.seeds = [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()}
+	d.bits = make([]uint64, size/64)
+	d.mask = uint64(size - 1)
+}
+
+// add sets key's bits and reports whether it was new.
+func (d *doorkeeper) add(key string) bool {
+	added := false
+	for _, seed := range d.seeds {
+		i := maphash.String(seed, key) & d.mask
+		if d.bits[i/64]&(1<<(i%64)) == 0 {
+			d.bits[i/64] |= 1 << (i % 64)
+			added = true
+		}
+	}
+	return added
+}
+
+func (d *doorkeeper) contains(key string) bool {
+	for _, seed := range d.seeds {
+		i := maphash.String(seed, key) & d.mask
+		if d.bits[i/64]&(1<<(i%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *doorkeeper) clear() {
+	clear(d.bits)
+}
+
+// admit records an attempt to store key and reports whether it may be
+// stored. Keys already present, and any key while the shard has room, are
+// always admitted. It must be called with the write lock held.
+func (s *shard) admit(key string, cost int64) bool {
+	if s.admission == nil {
+		return true
+	}
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+
+	s.admission.record(key)
+	if _, ok := s.items[key]; ok || !s.overflows(key, cost) {
+		return true
+	}
+	victim, ok := s.policy.victim()
+	if !ok {
+		return true
+	}
+	return s.admission.estimate(key) > s.admission.estimate(victim)
+}
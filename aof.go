@@ -0,0 +1,360 @@
+package go_in_memory_cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSegmentSize = 64 << 20
+	snapshotFile       = "snapshot.gob"
+	segmentPattern     = "*.aof"
+)
+
+type LogConfig struct {
+	// Dir holds the snapshot and log segments. It is created if missing.
+	Dir string
+	// MaxSegmentSize rotates the current segment once it grows past this
+	// many bytes. Defaults to 64MB.
+	MaxSegmentSize int64
+	// CompactInterval periodically folds the log into a fresh snapshot.
+	// Zero disables periodic compaction; Compact can still be called.
+	CompactInterval time.Duration
+	// Fsync syncs the segment to disk after every record.
+	Fsync bool
+}
+
+type logOp uint8
+
+const (
+	logSet logOp = iota + 1
+	logDelete
+	logFlush
+)
+
+type logRecord struct {
+	Op   logOp
+	Key  string
+	Item Item
+}
+
+// appendLog records mutations so they can be replayed after a restart.
+type appendLog struct {
+	mu   sync.Mutex
+	cfg  LogConfig
+	seq  int
+	f    *os.File
+	enc  *gob.Encoder
+	size int64
+	err  error
+
+	logger *slog.Logger
+}
+
+func (l *appendLog) Write(p []byte) (int, error) {
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+func segmentPath(dir string, seq int) string {
+	return filepath.Join(dir, fmt.Sprintf("%010d.aof", seq))
+}
+
+func segmentSeq(path string) (int, bool) {
+	var seq int
+	if _, err := fmt.Sscanf(filepath.Base(path), "%010d.aof", &seq); err != nil {
+		return 0, false
+	}
+	return seq, true
+}
+
+// segments returns the sequence numbers of the log segments in dir in
+// ascending order.
+func segments(dir string) ([]int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, segmentPattern))
+	if err != nil {
+		return nil, err
+	}
+	var seqs []int
+	for _, p := range paths {
+		if seq, ok := segmentSeq(p); ok {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Ints(seqs)
+	return seqs, nil
+}
+
+func (l *appendLog) openSegment(seq int) error {
+	f, err := os.OpenFile(segmentPath(l.cfg.Dir, seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	l.seq = seq
+	l.f = f
+	l.size = 0
+	l.enc = gob.NewEncoder(l)
+	return nil
+}
+
+// rotate must be called with l.mu held.
+func (l *appendLog) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	return l.openSegment(l.seq + 1)
+}
+
+func (l *appendLog) append(rec logRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil || l.f == nil {
+		return
+	}
+	if err := l.enc.Encode(&rec); err != nil {
+		l.fail(err)
+		return
+	}
+	if l.cfg.Fsync {
+		if err := l.f.Sync(); err != nil {
+			l.fail(err)
+			return
+		}
+	}
+	if l.size >= l.cfg.MaxSegmentSize {
+		if err := l.rotate(); err != nil {
+			l.fail(err)
+		}
+	}
+}
+
+// fail records the first write error. Later appends are dropped and the
+// error is returned from Sync and Close.
+func (l *appendLog) fail(err error) {
+	l.err = err
+	if l.logger != nil {
+		l.logger.Error("append log write failed; further mutations are not logged", slog.String("dir", l.cfg.Dir), slog.Any("error", err))
+	}
+}
+
+func (l *appendLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return l.err
+	}
+	err := l.f.Close()
+	l.f = nil
+	if l.err != nil {
+		return l.err
+	}
+	return err
+}
+
+// Open creates a cache backed by an append-only log in cfg.Dir. The latest
+// snapshot and every log segment written after it are replayed before Open
+// returns, so the cache resumes with the state it had when it was last
+// closed or crashed. A truncated final record is ignored.
+func Open(cfg LogConfig, defaultLifetime, cleanupInterval time.Duration, opts ...Option) (*Cache, error) {
+	if cfg.MaxSegmentSize <= 0 {
+		cfg.MaxSegmentSize = defaultSegmentSize
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := New(defaultLifetime, cleanupInterval, opts...)
+	if err := c.replay(cfg.Dir); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	seqs, err := segments(cfg.Dir)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	next := 1
+	if len(seqs) > 0 {
+		next = seqs[len(seqs)-1] + 1
+	}
+
+	l := &appendLog{cfg: cfg, logger: c.logger}
+	if err := l.openSegment(next); err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.log = l
+	for _, s := range c.shards {
+		s.log = l
+	}
+
+	if cfg.CompactInterval > 0 {
+		timer := c.clock.NewTimer(cfg.CompactInterval)
+		c.gcDone.Add(1)
+		go func() {
+			defer c.gcDone.Done()
+			c.compactLoop(timer, cfg.CompactInterval)
+		}()
+	}
+
+	return c, nil
+}
+
+func (c *Cache) replay(dir string) error {
+	f, err := os.Open(filepath.Join(dir, snapshotFile))
+	switch {
+	case err == nil:
+		err = c.Load(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("load snapshot: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	seqs, err := segments(dir)
+	if err != nil {
+		return err
+	}
+	for _, seq := range seqs {
+		if err := c.replaySegment(segmentPath(dir, seq)); err != nil {
+			return fmt.Errorf("replay segment %d: %w", seq, err)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) replaySegment(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+	now := c.clock.Now().UnixNano()
+	for {
+		var rec logRecord
+		err := dec.Decode(&rec)
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if rec.Op == logFlush {
+			for _, s := range c.shards {
+				s.drain()
+			}
+			continue
+		}
+
+		s := c.shardFor(rec.Key)
+		s.Lock()
+		switch rec.Op {
+		case logSet:
+			if rec.Item.Expired > 0 && now > rec.Item.Expired {
+				s.remove(rec.Key)
+			} else {
+				s.store(rec.Key, rec.Item)
+			}
+		case logDelete:
+			s.remove(rec.Key)
+		}
+		s.Unlock()
+	}
+}
+
+func (c *Cache) compactLoop(timer Timer, interval time.Duration) {
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+			if err := c.Compact(); err != nil && c.logger != nil {
+				c.logger.Error("append log compaction failed", slog.Any("error", err))
+			}
+			timer.Reset(interval)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Compact writes a fresh snapshot and removes the log segments it
+// supersedes.
+func (c *Cache) Compact() error {
+	if c.log == nil {
+		return errors.New("cache has no append log")
+	}
+	if c.closed.Load() {
+		return ErrCacheClosed
+	}
+
+	l := c.log
+	l.mu.Lock()
+	if l.err == nil {
+		l.err = l.rotate()
+	}
+	last, err := l.seq-1, l.err
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(l.cfg.Dir, snapshotFile+".tmp")
+	if err := c.SaveFile(tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(l.cfg.Dir, snapshotFile)); err != nil {
+		return err
+	}
+
+	seqs, err := segments(l.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	for _, seq := range seqs {
+		if seq > last {
+			break
+		}
+		if err := os.Remove(segmentPath(l.cfg.Dir, seq)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync flushes the append log to disk and returns the first write error
+// encountered since Open, if any.
+func (c *Cache) Sync() error {
+	if c.log == nil {
+		return nil
+	}
+
+	l := c.log
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return l.err
+	}
+	if l.f == nil {
+		return ErrCacheClosed
+	}
+	return l.f.Sync()
+}
@@ -0,0 +1,81 @@
+package go_in_memory_cache
+
+import "testing"
+
+func TestAppendLogReplay(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		write func(c *Cache) error
+		want  map[string]interface{}
+	}{
+		{
+			name: "sets and deletes",
+			write: func(c *Cache) error {
+				if err := c.Set("a", 1, 0); err != nil {
+					return err
+				}
+				if err := c.Set("b", 2, 0); err != nil {
+					return err
+				}
+				return c.Delete("a")
+			},
+			want: map[string]interface{}{"b": 2},
+		},
+		{
+			name: "flush",
+			write: func(c *Cache) error {
+				if err := c.Set("a", 1, 0); err != nil {
+					return err
+				}
+				if err := c.Flush(); err != nil {
+					return err
+				}
+				return c.Set("b", 2, 0)
+			},
+			want: map[string]interface{}{"b": 2},
+		},
+		{
+			name: "evictions",
+			opts: []Option{WithMaxEntries(2)},
+			write: func(c *Cache) error {
+				for i, key := range []string{"a", "b", "c"} {
+					if err := c.Set(key, i, 0); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: map[string]interface{}{"b": 1, "c": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := LogConfig{Dir: t.TempDir()}
+			c, err := Open(cfg, 0, 0, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.write(c); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			c, err = Open(cfg, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			if n := c.Count(); n != len(tt.want) {
+				t.Errorf("replayed %d entries, want %d", n, len(tt.want))
+			}
+			for key, want := range tt.want {
+				if got, ok := c.Get(key); !ok || got != want {
+					t.Errorf("Get(%q) = %v, %v; want %v, true", key, got, ok, want)
+				}
+			}
+		})
+	}
+}
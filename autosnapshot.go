@@ -0,0 +1,134 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// rotatedSnapshot returns the name of the i-th most recent snapshot: path
+// itself for 0, then path.1, path.2 and so on.
+func rotatedSnapshot(path string, i int) string {
+	if i == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// writeSnapshot takes a scheduled snapshot, to the SnapshotStore if one
+// was configured and to c.snapshotPath otherwise.
+func (c *Cache) writeSnapshot() error {
+	if c.snapshotStore != nil {
+		_, err := c.SaveSnapshot(context.Background(), c.snapshotStore)
+		return err
+	}
+	return c.writeSnapshotFile()
+}
+
+// writeSnapshotFile saves the cache to a temporary file, syncs it and
+// renames it over c.snapshotPath, first shifting older snapshots down so
+// that the last c.snapshotKeep are retained.
+func (c *Cache) writeSnapshotFile() error {
+	path := c.snapshotPath
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := c.Save(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	for i := c.snapshotKeep - 1; i > 1; i-- {
+		err := os.Rename(rotatedSnapshot(path, i-1), rotatedSnapshot(path, i))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if c.snapshotKeep > 1 {
+		// Keep path in place until tmp replaces it, so that it holds a
+		// complete snapshot at every moment.
+		if err := linkOrCopy(path, rotatedSnapshot(path, 1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+// linkOrCopy makes dst a hard link to src, or a copy of it where links are
+// not supported, replacing any existing dst.
+func linkOrCopy(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(src, dst); err == nil || errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// loadSnapshot restores the newest readable snapshot, falling back to
+// older ones if the newest is missing or damaged.
+func (c *Cache) loadSnapshot() {
+	if c.snapshotStore != nil {
+		err := c.LoadLatestSnapshot(context.Background(), c.snapshotStore)
+		if err != nil && !errors.Is(err, os.ErrNotExist) && c.logger != nil {
+			c.logger.Error("cache snapshot load failed", slog.Any("error", err))
+		}
+		return
+	}
+	for i := 0; i < c.snapshotKeep; i++ {
+		err := c.LoadFile(rotatedSnapshot(c.snapshotPath, i))
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, os.ErrNotExist) && c.logger != nil {
+			c.logger.Error("cache snapshot load failed", slog.String("path", rotatedSnapshot(c.snapshotPath, i)), slog.Any("error", err))
+		}
+	}
+}
+
+func (c *Cache) snapshotLoop(timer Timer) {
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C():
+			if err := c.writeSnapshot(); err != nil && c.logger != nil {
+				c.logger.Error("cache snapshot failed", slog.Any("error", err))
+			}
+			timer.Reset(c.snapshotInterval)
+		case <-c.stop:
+			return
+		}
+	}
+}
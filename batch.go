@@ -0,0 +1,130 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"time"
+)
+
+// MGet returns the values of every key that holds a live entry, taking each
+// shard's lock once.
+func (c *Cache) MGet(keys ...string) map[string]interface{} {
+	values := make(map[string]interface{}, len(keys))
+	if c.closed.Load() {
+		return values
+	}
+
+	for s, keys := range c.groupByShard(keys) {
+		var slide []string
+		s.RLock()
+		for _, key := range keys {
+			item, ok := s.live(key)
+			c.hit(ok)
+			if !ok || item.Negative {
+				continue
+			}
+			if item.Sliding > 0 {
+				slide = append(slide, key)
+				continue
+			}
+			s.trackAccess(key)
+			item.meta.record(s.clock.Now())
+			values[key] = c.valueOf(item)
+		}
+		s.RUnlock()
+
+		for _, key := range slide {
+			if item, ok := s.slide(key); ok {
+				values[key] = c.valueOf(item)
+			}
+		}
+	}
+	return values
+}
+
+// MSet stores every entry with the same lifetime, taking each shard's lock
+// once. It returns the keys that could not be stored with their errors, or
+// nil if all were stored.
+func (c *Cache) MSet(entries map[string]interface{}, duration time.Duration) map[string]error {
+	var failed map[string]error
+	fail := func(key string, err error) {
+		if failed == nil {
+			failed = make(map[string]error)
+		}
+		failed[key] = err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+
+	if err := c.writable(); err != nil {
+		for _, key := range keys {
+			fail(key, err)
+		}
+		return failed
+	}
+
+	var stored []string
+	for s, keys := range c.groupByShard(keys) {
+		var evicted []keyedItem
+		s.Lock()
+		for _, key := range keys {
+			item := c.newItem(key, entries[key], duration, false)
+			if s.maxCost > 0 && s.weigh(key, item.Value) > s.maxCost {
+				fail(key, keyError(ErrCapacityExceeded, key))
+				continue
+			}
+			if !s.admit(key, s.weigh(key, item.Value)) {
+				c.stats.rejected.Add(1)
+				fail(key, keyError(ErrRejected, key))
+				continue
+			}
+			if err := c.storePut(context.Background(), key, item); err != nil {
+				fail(key, err)
+				continue
+			}
+			evicted = append(evicted, s.store(key, item)...)
+			stored = append(stored, key)
+			c.stats.sets.Add(1)
+		}
+		s.Unlock()
+
+		c.overflowed(evicted)
+	}
+	c.invalidate(stored...)
+	return failed
+}
+
+// MDelete removes keys, taking each shard's lock once, and reports for each
+// key whether it was removed. With WithWriteThrough a key the store fails to
+// delete is kept and reported as false.
+func (c *Cache) MDelete(keys ...string) map[string]bool {
+	result := make(map[string]bool, len(keys))
+	if c.writable() != nil {
+		return result
+	}
+
+	var deleted []string
+	for s, keys := range c.groupByShard(keys) {
+		var removed []keyedItem
+		s.Lock()
+		for _, key := range keys {
+			if err := c.storeDelete(context.Background(), key); err != nil {
+				result[key] = false
+				continue
+			}
+			item, ok := s.remove(key)
+			result[key] = ok
+			if ok {
+				removed = append(removed, keyedItem{key: key, item: item})
+				deleted = append(deleted, key)
+			}
+		}
+		s.Unlock()
+
+		c.deleted(removed)
+	}
+	c.invalidate(deleted...)
+	return result
+}
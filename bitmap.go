@@ -0,0 +1,123 @@
+package go_in_memory_cache
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// maxBitOffset bounds SetBit offsets, keeping a single bitmap under 512 MiB.
+const maxBitOffset = 1<<32 - 1
+
+// bitmapValue is the container behind the bit operations. Its plain form is
+// the []byte it was adopted from, bit 0 being the most significant bit of
+// the first byte.
+type bitmapValue struct {
+	sync.RWMutex
+	bits []byte
+	// shared is set while bits is the adopted []byte, which callers may
+	// hold; set copies it first.
+	shared bool
+}
+
+var bitmapType = containerType[*bitmapValue]{
+	name: "bitmap",
+	make: func() *bitmapValue { return &bitmapValue{} },
+	adopt: func(v interface{}) (*bitmapValue, bool) {
+		switch v := v.(type) {
+		case *bitmapValue:
+			return v, true
+		case []byte:
+			return &bitmapValue{bits: v, shared: true}, true
+		}
+		return nil, false
+	},
+}
+
+func (b *bitmapValue) get(offset uint) bool {
+	i := offset / 8
+	return i < uint(len(b.bits)) && b.bits[i]&(0x80>>(offset%8)) != 0
+}
+
+func (b *bitmapValue) set(offset uint, on bool) {
+	if b.shared {
+		b.bits, b.shared = bytes.Clone(b.bits), false
+	}
+	i := offset / 8
+	if i >= uint(len(b.bits)) {
+		b.bits = append(b.bits, make([]byte, int(i)+1-len(b.bits))...)
+	}
+	if on {
+		b.bits[i] |= 0x80 >> (offset % 8)
+	} else {
+		b.bits[i] &^= 0x80 >> (offset % 8)
+	}
+}
+
+func (b *bitmapValue) view() interface{} {
+	b.RLock()
+	defer b.RUnlock()
+	return bytes.Clone(b.bits)
+}
+
+func (b *bitmapValue) clone() container {
+	b.RLock()
+	defer b.RUnlock()
+	return &bitmapValue{bits: bytes.Clone(b.bits)}
+}
+
+func (*bitmapValue) kind() string {
+	return "bitmap"
+}
+
+func (b *bitmapValue) len() int {
+	return len(b.bits)
+}
+
+func (b *bitmapValue) weight() int64 {
+	return int64(len(b.bits))
+}
+
+// SetBit sets or clears the bit at offset in the []byte under key and
+// returns its previous state. Bit 0 is the most significant bit of the
+// first byte, and the value grows with zero bytes to cover offset. A
+// missing key is created with the default lifetime; an existing one keeps
+// its expiry. Any value other than a []byte fails with ErrTypeMismatch.
+func (c *Cache) SetBit(key string, offset uint, on bool) (bool, error) {
+	if offset > maxBitOffset {
+		return false, fmt.Errorf("bit offset %d for %q out of range", offset, key)
+	}
+	var old bool
+	err := withContainer(c, key, bitmapType, true, func(b *bitmapValue) (bool, error) {
+		old = b.get(offset)
+		if old == on && offset/8 < uint(len(b.bits)) {
+			return false, nil
+		}
+		b.set(offset, on)
+		return true, nil
+	})
+	return old, err
+}
+
+// GetBit reports whether the bit at offset in the []byte under key is set.
+// Bits beyond the end of the value, and of a missing key, are clear.
+func (c *Cache) GetBit(key string, offset uint) (bool, error) {
+	var on bool
+	_, err := readContainer(c, key, bitmapType, func(b *bitmapValue) {
+		on = b.get(offset)
+	})
+	return on, err
+}
+
+// BitCount returns the number of set bits in the []byte under key, 0 if it
+// is missing.
+func (c *Cache) BitCount(key string) (int, error) {
+	var n int
+	_, err := readContainer(c, key, bitmapType, func(b *bitmapValue) {
+		for _, x := range b.bits {
+			n += bits.OnesCount8(x)
+		}
+	})
+	return n, err
+}
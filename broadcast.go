@@ -0,0 +1,126 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+)
+
+// Invalidation tells the caches of other instances to drop keys, or
+// everything when Flush is set.
+type Invalidation struct {
+	// Origin identifies the publishing cache so it can ignore its own
+	// messages.
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	Flush  bool     `json:"flush,omitempty"`
+}
+
+// Broadcaster carries invalidations between the caches of several
+// instances, e.g. over Redis pub/sub or NATS.
+type Broadcaster interface {
+	Publish(ctx context.Context, msg Invalidation) error
+	// Subscribe passes every message published by any instance to fn and
+	// blocks until ctx is done or the subscription fails.
+	Subscribe(ctx context.Context, fn func(Invalidation)) error
+}
+
+const broadcastQueue = 256
+
+// broadcaster publishes local invalidations in the background and applies
+// those received from peers.
+type broadcaster struct {
+	b      Broadcaster
+	origin string
+	queue  chan Invalidation
+	cancel context.CancelFunc
+}
+
+func newBroadcaster(b Broadcaster) *broadcaster {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &broadcaster{b: b, origin: hex.EncodeToString(id), queue: make(chan Invalidation, broadcastQueue)}
+}
+
+func (c *Cache) startBroadcast() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.bcast.cancel = cancel
+
+	c.gcDone.Add(2)
+	go func() {
+		defer c.gcDone.Done()
+		c.subscribeLoop(ctx)
+	}()
+	go func() {
+		defer c.gcDone.Done()
+		c.publishLoop(ctx)
+	}()
+}
+
+func (c *Cache) subscribeLoop(ctx context.Context) {
+	for {
+		err := c.bcast.b.Subscribe(ctx, c.applyInvalidation)
+		if ctx.Err() != nil {
+			return
+		}
+		if c.logger != nil {
+			c.logger.Error("cache invalidation subscription failed", slog.Any("error", err))
+		}
+		timer := c.clock.NewTimer(time.Second)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (c *Cache) publishLoop(ctx context.Context) {
+	for {
+		select {
+		case msg := <-c.bcast.queue:
+			if err := c.bcast.b.Publish(ctx, msg); err != nil && c.logger != nil {
+				c.logger.Error("cache invalidation publish failed", slog.Any("error", err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// broadcast queues an invalidation for peers when WithBroadcaster is set.
+// It blocks while the queue is full.
+func (c *Cache) broadcast(msg Invalidation) {
+	if c.bcast == nil {
+		return
+	}
+	msg.Origin = c.bcast.origin
+	select {
+	case c.bcast.queue <- msg:
+	case <-c.stop:
+	}
+}
+
+// invalidate broadcasts that keys changed, if there are any.
+func (c *Cache) invalidate(keys ...string) {
+	if len(keys) > 0 {
+		c.broadcast(Invalidation{Keys: keys})
+	}
+}
+
+func (c *Cache) applyInvalidation(msg Invalidation) {
+	// A frozen cache ignores remote flushes as it does remote deletes.
+	if msg.Origin == c.bcast.origin || c.writable() != nil {
+		return
+	}
+	if msg.Flush {
+		c.flush()
+		return
+	}
+	for s, keys := range c.groupByShard(msg.Keys) {
+		c.deleted(s.clearItems(keys))
+	}
+}
@@ -0,0 +1,91 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingBroadcaster is a Broadcaster that hands published messages to
+// the test and never delivers any.
+type recordingBroadcaster struct {
+	published chan Invalidation
+}
+
+func (b *recordingBroadcaster) Publish(ctx context.Context, msg Invalidation) error {
+	b.published <- msg
+	return nil
+}
+
+func (b *recordingBroadcaster) Subscribe(ctx context.Context, fn func(Invalidation)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// next returns the next published message, or false if none arrives soon.
+func (b *recordingBroadcaster) next() (Invalidation, bool) {
+	select {
+	case msg := <-b.published:
+		return msg, true
+	case <-time.After(50 * time.Millisecond):
+		return Invalidation{}, false
+	}
+}
+
+func TestBroadcastOnChange(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(c *Cache)
+		want *Invalidation
+	}{
+		{name: "Set", op: func(c *Cache) { c.Set("b", 1, 0) }, want: &Invalidation{Keys: []string{"b"}}},
+		{name: "Update", op: func(c *Cache) {
+			c.Update("a", func(old interface{}, exists bool) (interface{}, error) { return 2, nil }, 0)
+		}, want: &Invalidation{Keys: []string{"a"}}},
+		{name: "Increment", op: func(c *Cache) { c.Increment("a", 1) }, want: &Invalidation{Keys: []string{"a"}}},
+		{name: "Rename", op: func(c *Cache) { c.Rename("a", "b") }, want: &Invalidation{Keys: []string{"a", "b"}}},
+		{name: "RPush", op: func(c *Cache) { c.RPush("l", "x") }, want: &Invalidation{Keys: []string{"l"}}},
+		{name: "Delete", op: func(c *Cache) { c.Delete("a") }, want: &Invalidation{Keys: []string{"a"}}},
+		{name: "Delete missing", op: func(c *Cache) { c.Delete("missing") }},
+		{name: "MDelete", op: func(c *Cache) { c.MDelete("a", "missing") }, want: &Invalidation{Keys: []string{"a"}}},
+		{name: "MDelete missing", op: func(c *Cache) { c.MDelete("missing") }},
+		{name: "ClearItems missing", op: func(c *Cache) { c.ClearItems([]string{"missing"}) }},
+		{name: "Flush", op: func(c *Cache) { c.Flush() }, want: &Invalidation{Flush: true}},
+		{name: "Flush empty", op: func(c *Cache) {
+			c.Delete("a")
+			<-c.bcast.b.(*recordingBroadcaster).published
+			c.Flush()
+		}},
+		{name: "Increment missing", op: func(c *Cache) { c.Increment("missing", 1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &recordingBroadcaster{published: make(chan Invalidation, 4)}
+			c := New(0, 0, WithBroadcaster(b))
+			defer c.Close()
+			if err := c.Set("a", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := b.next(); !ok {
+				t.Fatal("Set published nothing")
+			}
+
+			tt.op(c)
+			msg, ok := b.next()
+			if tt.want == nil {
+				if ok {
+					t.Errorf("published %+v for a change that changed nothing", msg)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("published nothing")
+			}
+			msg.Origin = ""
+			if !reflect.DeepEqual(msg, *tt.want) {
+				t.Errorf("published %+v, want %+v", msg, *tt.want)
+			}
+		})
+	}
+}
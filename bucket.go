@@ -0,0 +1,50 @@
+package go_in_memory_cache
+
+// Bucket returns the named bucket, creating it with opts on first use. A
+// bucket is an independent key space with its own default lifetime,
+// capacity, Flush and Stats, but it uses the parent's Clock, its expired
+// entries are collected by the parent's GC goroutine, and it is frozen and
+// closed together with the parent. opts are ignored when the bucket
+// already exists.
+func (c *Cache) Bucket(name string, opts ...Option) *Cache {
+	c.bucketsMu.Lock()
+	defer c.bucketsMu.Unlock()
+
+	if b, ok := c.buckets[name]; ok {
+		return b
+	}
+
+	b := New(c.defaultLifetime, 0, append([]Option{WithClock(c.clock)}, opts...)...)
+	b.parent = c
+	if c.closed.Load() {
+		b.Close()
+	}
+	if c.buckets == nil {
+		c.buckets = make(map[string]*Cache)
+	}
+	c.buckets[name] = b
+	return b
+}
+
+// Buckets returns the names of all buckets created on c.
+func (c *Cache) Buckets() []string {
+	c.bucketsMu.Lock()
+	defer c.bucketsMu.Unlock()
+
+	names := make([]string, 0, len(c.buckets))
+	for name := range c.buckets {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (c *Cache) bucketList() []*Cache {
+	c.bucketsMu.Lock()
+	defer c.bucketsMu.Unlock()
+
+	buckets := make([]*Cache, 0, len(c.buckets))
+	for _, b := range c.buckets {
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
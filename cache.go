@@ -1,9 +1,18 @@
 package go_in_memory_cache
 
 import (
+	"context"
 	"errors"
+	"hash/maphash"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go-in-memory-cache/internal/singleflight"
 )
 
 type CacheInterface interface {
@@ -15,189 +24,891 @@ type CacheInterface interface {
 	Rename(key, newKey string) error
 }
 
+// ContextCacheInterface extends CacheInterface with variants that pass ctx
+// on to the Loader and backing Store.
+type ContextCacheInterface interface {
+	CacheInterface
+	GetCtx(ctx context.Context, key string) (interface{}, bool)
+	SetCtx(ctx context.Context, key string, value interface{}, duration time.Duration) error
+	DeleteCtx(ctx context.Context, key string) error
+}
+
 type Cache struct {
-	sync.RWMutex
-	defaultLifetime time.Duration
-	cleanupInterval time.Duration
-	items           map[string]Item
+	defaultLifetime    time.Duration
+	ttlRules           []ttlRule
+	ttlJitter          float64
+	cleanupInterval    atomic.Int64
+	maxEntries         int
+	maxCost            int64
+	weigher            Weigher
+	policyKind         Policy
+	policyFactory      func() EvictionPolicy
+	admission          bool
+	shardCount         int
+	seed               maphash.Seed
+	shards             []*shard
+	flights            singleflight.Group[interface{}]
+	computes           singleflight.Group[interface{}]
+	queues             queueWaiters
+	onEvicted          func(key string, value interface{})
+	flushOnClose       bool
+	strictSet          bool
+	sliding            bool
+	stats              counters
+	clock              Clock
+	codec              Codec
+	valueCodec         Codec
+	compressor         Compressor
+	compressMin        int
+	loader             Loader
+	undoWindow         time.Duration
+	tombstoneRetention time.Duration
+	staleWindow        time.Duration
+	refreshAhead       float64
+	negativeTTL        time.Duration
+	logger             *slog.Logger
+	refreshMu          sync.Mutex
+	refreshing         map[string]struct{}
+	store              Store
+	behind             *writeBehind
+	log                *appendLog
+	watch              *watchers
+	changeRetention    int
+
+	leaseMu  sync.Mutex
+	leases   map[string]*lease
+	leaseSeq uint64
+
+	schedMu   sync.Mutex
+	scheduled map[string]*scheduledSet
+
+	bucketsMu sync.Mutex
+	buckets   map[string]*Cache
+	// parent is the cache a bucket was created from.
+	parent *Cache
+
+	warmup    bool
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	closed    atomic.Bool
+	frozen    atomic.Bool
+	stop      chan struct{}
+	gcDone    sync.WaitGroup
+	gcStarted atomic.Bool
+	gcPaused  atomic.Bool
+	gcReset   chan struct{}
+	// gcBudget caps how many entries one GC cycle removes; gcCursor is the
+	// shard the next budgeted cycle starts from.
+	gcBudget int
+	gcCursor atomic.Int64
+	// lazyExpiry makes reads remove the expired entries they find.
+	lazyExpiry bool
+
+	// gcMin and gcMax bound the interval when WithAdaptiveGC is set.
+	gcMin, gcMax time.Duration
+
+	snapshotInterval time.Duration
+	snapshotPath     string
+	snapshotKeep     int
+	snapshotStore    SnapshotStore
+	snapshotKey      []byte
+
+	bcast *broadcaster
+
+	// opts are the options the cache was created with, for Clone.
+	opts []Option
+
+	lastGC       atomic.Pointer[GCReport]
+	onGCComplete func(GCReport)
+
+	shutdownHooks []func(ctx context.Context, c *Cache) error
 }
 
 type Item struct {
 	Value   interface{}
 	Created time.Time
 	Expired int64
+	// Sliding, when positive, pushes Expired forward by this much on every
+	// read.
+	Sliding time.Duration
+	Tags    []string
+	// Priority ranks the entry for eviction; see SetWithPriority.
+	Priority Priority
+	// Negative marks an entry stored by SetNegative; Value is nil.
+	Negative bool
+	// Compression records how Value is stored when WithCompression is on.
+	// Items returned by the cache always carry the decompressed value.
+	Compression Compression
+	// Version is assigned by the cache on every write and increases each
+	// time the key is written.
+	Version uint64
+
+	cost int64
+	// rawSize is the size of Value before compression.
+	rawSize int64
+	meta    *accessMeta
 }
 
-func New(defaultLifetime, cleanupInterval time.Duration) *Cache {
-	items := make(map[string]Item)
-
-	cache := Cache{
+func New(defaultLifetime, cleanupInterval time.Duration, opts ...Option) *Cache {
+	cache := &Cache{
 		defaultLifetime: defaultLifetime,
-		cleanupInterval: cleanupInterval,
-		items:           items,
+		shardCount:      1,
+		seed:            maphash.MakeSeed(),
+		clock:           realClock{},
+		codec:           GobCodec,
+		stop:            make(chan struct{}),
+		gcReset:         make(chan struct{}, 1),
+		watch:           &watchers{},
+	}
+	cache.cleanupInterval.Store(int64(cleanupInterval))
+
+	cache.opts = opts
+	for _, opt := range opts {
+		opt(cache)
+	}
+	if cleanupInterval <= 0 && cache.gcMax > 0 {
+		cleanupInterval = cache.gcMax
+		cache.cleanupInterval.Store(int64(cleanupInterval))
+	}
+
+	perShard := 0
+	if cache.maxEntries > 0 {
+		perShard = (cache.maxEntries + cache.shardCount - 1) / cache.shardCount
+	}
+	var perShardCost int64
+	if cache.maxCost > 0 {
+		perShardCost = (cache.maxCost + int64(cache.shardCount) - 1) / int64(cache.shardCount)
+		if cache.weigher == nil {
+			cache.weigher = DefaultWeigher
+		}
+	}
+	policy := cache.policyFactory
+	if policy == nil {
+		kind := cache.policyKind
+		policy = func() EvictionPolicy { return newPolicy(kind) }
+	}
+	cache.watch.value = cache.valueOf
+	cache.ready = make(chan struct{})
+	if !cache.warmup {
+		cache.readyOnce.Do(func() { close(cache.ready) })
+	}
+	if cache.changeRetention > 0 {
+		cache.changeLog()
+	}
+	cache.shards = make([]*shard, cache.shardCount)
+	for i := range cache.shards {
+		cache.shards[i] = newShard(perShard, perShardCost, cache.weigher, policy)
+		cache.shards[i].clock = cache.clock
+		cache.shards[i].watch = cache.watch
+		cache.shards[i].grace = int64(cache.staleWindow)
+		cache.shards[i].undoWindow = int64(cache.undoWindow)
+		cache.shards[i].tombstoneRetention = int64(cache.tombstoneRetention)
+		if cache.admission && cache.shards[i].policy != nil {
+			cache.shards[i].admission = newAdmission(max(perShard, 1024))
+		}
+	}
+
+	if cache.behind != nil {
+		cache.behind.logger = cache.logger
+		cache.behind.start(cache.clock)
+	}
+
+	if cache.snapshotPath != "" || cache.snapshotStore != nil {
+		if cache.snapshotKeep < 1 {
+			cache.snapshotKeep = 1
+		}
+		cache.loadSnapshot()
+		timer := cache.clock.NewTimer(cache.snapshotInterval)
+		cache.gcDone.Add(1)
+		go func() {
+			defer cache.gcDone.Done()
+			cache.snapshotLoop(timer)
+		}()
+	}
+
+	if cache.bcast != nil {
+		cache.startBroadcast()
 	}
 
 	if cleanupInterval > 0 {
 		cache.StartGC()
 	}
 
-	return &cache
+	return cache
 }
 
-func (c *Cache) Set(key string, value interface{}, duration time.Duration) error {
-	var expiration int64
+func (c *Cache) shardFor(key string) *shard {
+	return c.shards[c.shardIndex(key)]
+}
 
+func (c *Cache) shardIndex(key string) int {
+	if len(c.shards) == 1 {
+		return 0
+	}
+	return int(maphash.String(c.seed, key) % uint64(len(c.shards)))
+}
+
+// lockKeys write-locks the shards holding keys, in shard order so that
+// concurrent callers cannot deadlock, and returns a function that unlocks
+// them.
+func (c *Cache) lockKeys(keys ...string) func() {
+	idx := make([]int, 0, len(keys))
+	for _, key := range keys {
+		idx = append(idx, c.shardIndex(key))
+	}
+	sort.Ints(idx)
+	idx = slices.Compact(idx)
+
+	for _, i := range idx {
+		c.shards[i].Lock()
+	}
+	return func() {
+		for _, i := range idx {
+			c.shards[i].Unlock()
+		}
+	}
+}
+
+// lifetime resolves duration against the default lifetime of key. A result
+// <= 0 means the entry never expires.
+func (c *Cache) lifetime(key string, duration time.Duration) time.Duration {
 	if duration == 0 {
-		duration = c.defaultLifetime
+		return c.LifetimeFor(key)
+	}
+	return duration
+}
+
+func (c *Cache) expiration(key string, duration time.Duration) int64 {
+	if duration = c.lifetime(key, duration); duration > 0 {
+		if c.ttlJitter > 0 {
+			duration += time.Duration(float64(duration) * c.ttlJitter * (2*rand.Float64() - 1))
+		}
+		return c.clock.Now().Add(duration).UnixNano()
+	}
+	return 0
+}
+
+func (c *Cache) newItem(key string, value interface{}, duration time.Duration, sliding bool) Item {
+	item := Item{
+		Value:   c.copyValue(value),
+		Expired: c.expiration(key, duration),
+		Created: c.clock.Now(),
+	}
+	if sliding || c.sliding {
+		if d := c.lifetime(key, duration); d > 0 {
+			item.Sliding = d
+		}
+	}
+	c.compress(&item)
+	return item
+}
+
+// evicted reports removed entries to the OnEvicted callback. It must be
+// called without any shard lock held.
+func (c *Cache) evicted(items []keyedItem) {
+	if c.onEvicted == nil {
+		return
+	}
+	for _, e := range items {
+		c.callEvicted(e.key, c.valueOf(e.item))
+	}
+}
+
+type setMode int
+
+const (
+	setAlways setMode = iota
+	setIfAbsent
+	setIfPresent
+)
+
+// Set stores value under key, replacing any existing entry. With
+// WithStrictSet it behaves like Add.
+func (c *Cache) Set(key string, value interface{}, duration time.Duration) error {
+	if c.strictSet {
+		return c.set(key, value, duration, setIfAbsent)
 	}
+	return c.set(key, value, duration, setAlways)
+}
+
+// SetCtx is Set with ctx passed to the backing Store under write-through.
+// It fails with ctx's error if ctx is already done.
+func (c *Cache) SetCtx(ctx context.Context, key string, value interface{}, duration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	mode := setAlways
+	if c.strictSet {
+		mode = setIfAbsent
+	}
+	return c.setItemCtx(ctx, key, c.newItem(key, value, duration, false), mode)
+}
+
+// Add stores value only if key does not hold a live entry.
+func (c *Cache) Add(key string, value interface{}, duration time.Duration) error {
+	return c.set(key, value, duration, setIfAbsent)
+}
 
-	if duration > 0 {
-		expiration = time.Now().Add(duration).UnixNano()
+// SetNX stores value only if key does not hold a live entry and reports
+// whether it did. The existence check and the write happen under one lock.
+func (c *Cache) SetNX(key string, value interface{}, duration time.Duration) (bool, error) {
+	err := c.set(key, value, duration, setIfAbsent)
+	if errors.Is(err, ErrKeyExists) {
+		return false, nil
 	}
+	return err == nil, err
+}
+
+// Replace stores value only if key already holds a live entry.
+func (c *Cache) Replace(key string, value interface{}, duration time.Duration) error {
+	return c.set(key, value, duration, setIfPresent)
+}
+
+// SetSliding stores value with a lifetime that restarts on every read, so
+// the entry only expires after duration without being accessed.
+func (c *Cache) SetSliding(key string, value interface{}, duration time.Duration) error {
+	return c.setItem(key, c.newItem(key, value, duration, true), setAlways)
+}
+
+func (c *Cache) set(key string, value interface{}, duration time.Duration, mode setMode) error {
+	return c.setItem(key, c.newItem(key, value, duration, false), mode)
+}
 
-	_, ok := c.items[key]
-	if ok {
-		return errors.New("key already exists")
+func (c *Cache) setItem(key string, item Item, mode setMode) error {
+	return c.setItemCtx(context.Background(), key, item, mode)
+}
+
+func (c *Cache) setItemCtx(ctx context.Context, key string, item Item, mode setMode) error {
+	if err := c.writable(); err != nil {
+		return err
 	}
 
-	c.Lock()
-	defer c.Unlock()
+	s := c.shardFor(key)
+	s.Lock()
 
-	c.items[key] = Item{
-		Value:   value,
-		Expired: expiration,
-		Created: time.Now(),
+	if s.maxCost > 0 && s.weigh(key, item.Value) > s.maxCost {
+		s.Unlock()
+		return keyError(ErrCapacityExceeded, key)
+	}
+
+	switch _, ok := s.live(key); {
+	case ok && mode == setIfAbsent:
+		s.Unlock()
+		return keyError(ErrKeyExists, key)
+	case !ok && mode == setIfPresent:
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+	if !s.admit(key, s.weigh(key, item.Value)) {
+		s.Unlock()
+		c.stats.rejected.Add(1)
+		return keyError(ErrRejected, key)
 	}
 
+	if err := c.storePut(ctx, key, item); err != nil {
+		s.Unlock()
+		return err
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	c.invalidate(key)
 	return nil
 }
 
+// Get returns the value stored under key. On a miss a cache created
+// WithLoader loads the value and reports whether that succeeded.
 func (c *Cache) Get(key string) (interface{}, bool) {
-	c.RLock()
-	defer c.RUnlock()
+	return c.GetCtx(context.Background(), key)
+}
 
-	result, ok := c.items[key]
+// GetCtx is Get with ctx passed to the Loader on a miss.
+func (c *Cache) GetCtx(ctx context.Context, key string) (interface{}, bool) {
+	item, ok := c.lookup(key)
+	c.hit(ok)
 	if !ok {
-		return nil, false
-	}
-
-	if result.Expired > 0 {
-		if time.Now().UnixNano() > result.Expired {
+		if value, ok := c.serveStale(key); ok {
+			return value, true
+		}
+		if c.loader == nil {
 			return nil, false
 		}
+		value, err := c.load(ctx, key)
+		return value, err == nil
 	}
-
-	return result.Value, true
+	if item.Negative {
+		return nil, false
+	}
+	c.refreshIfDue(key, item)
+	return c.valueOf(item), true
 }
 
+// GetItem returns the entry stored under key. Like Get it reports a
+// negative entry as missing; Lookup tells the two apart.
 func (c *Cache) GetItem(key string) (*Item, bool) {
-	c.RLock()
-	defer c.RUnlock()
-
-	result, ok := c.items[key]
-	if !ok {
+	item, ok := c.lookup(key)
+	c.hit(ok)
+	if !ok || item.Negative {
 		return nil, false
 	}
+	item.Value = c.valueOf(item)
+	item.Compression = Uncompressed
+	return &item, true
+}
 
-	if result.Expired > 0 {
-		if time.Now().UnixNano() > result.Expired {
-			return nil, false
+// peek returns the live item under key without counting an access or
+// sliding its lifetime.
+func (c *Cache) peek(key string) (Item, bool) {
+	if c.closed.Load() {
+		return Item{}, false
+	}
+
+	s := c.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	return s.live(key)
+}
+
+// lookup returns the live item for key and records the access with the
+// eviction policy, without touching hit/miss statistics.
+func (c *Cache) lookup(key string) (Item, bool) {
+	if c.closed.Load() {
+		return Item{}, false
+	}
+
+	s := c.shardFor(key)
+	s.RLock()
+	result, ok := s.live(key)
+	if !ok {
+		_, present := s.items[key]
+		s.RUnlock()
+		if present && c.lazyExpiry {
+			c.reap(s, key)
 		}
+		return Item{}, false
 	}
+	if result.Sliding > 0 {
+		s.RUnlock()
+		return s.slide(key)
+	}
+
+	s.trackAccess(key)
+	result.meta.record(s.clock.Now())
+	s.RUnlock()
 
-	return &result, true
+	return result, true
 }
 
 func (c *Cache) Delete(key string) error {
-	c.Lock()
-	defer c.Unlock()
+	return c.DeleteCtx(context.Background(), key)
+}
 
-	if _, ok := c.items[key]; !ok {
-		return errors.New("key not found")
+// DeleteCtx is Delete with ctx passed to the backing Store under
+// write-through. It fails with ctx's error if ctx is already done.
+func (c *Cache) DeleteCtx(ctx context.Context, key string) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	delete(c.items, key)
+	s := c.shardFor(key)
+	s.Lock()
+	if err := c.storeDelete(ctx, key); err != nil {
+		s.Unlock()
+		return err
+	}
+	if _, live := s.live(key); !live {
+		// An expired entry still held is removed as GC would remove it,
+		// and the key reported missing.
+		item, ok := s.expire(key)
+		s.Unlock()
+		if ok {
+			c.stats.expired.Add(1)
+			c.evicted([]keyedItem{{key: key, item: item}})
+		}
+		return keyError(ErrKeyNotFound, key)
+	}
+	item, _ := s.remove(key)
+	s.Unlock()
+
+	c.invalidate(key)
+	c.stats.deletes.Add(1)
+	c.evicted([]keyedItem{{key: key, item: item}})
 	return nil
 }
 
+// StartGC starts the cleanup goroutine. It does nothing if the goroutine is
+// already running or the cache has no cleanup interval;
+// SetCleanupInterval sets one and starts it.
 func (c *Cache) StartGC() {
-	go c.GC()
+	if c.interval() <= 0 || !c.gcStarted.CompareAndSwap(false, true) {
+		return
+	}
+	// The timer is created before the goroutine starts so that a FakeClock
+	// advanced right after New already sees it.
+	timer := c.clock.NewTimer(c.interval())
+	c.gcDone.Add(1)
+	go func() {
+		defer c.gcDone.Done()
+		c.runGC(timer)
+	}()
 }
 
+// GC removes expired entries every cleanup interval until the cache is
+// closed. Cycles are skipped while GC is paused.
 func (c *Cache) GC() {
+	c.runGC(c.clock.NewTimer(c.interval()))
+}
 
-	for {
-		<-time.After(c.cleanupInterval)
+func (c *Cache) runGC(timer Timer) {
+	defer timer.Stop()
 
-		if c.items == nil {
+	for {
+		select {
+		case <-timer.C():
+			if !c.gcPaused.Load() {
+				c.adapt(c.deleteExpired(c.gcBudget))
+			}
+			timer.Reset(c.interval())
+		case <-c.gcReset:
+			if !timer.Stop() {
+				select {
+				case <-timer.C():
+				default:
+				}
+			}
+			timer.Reset(c.interval())
+		case <-c.stop:
 			return
 		}
-
-		if keys := c.expiredKeys(); len(keys) > 0 {
-			c.ClearItems(keys)
-		}
-
 	}
 }
 
-func (c *Cache) expiredKeys() (keys []string) {
-	c.RLock()
-	defer c.RUnlock()
+func (c *Cache) interval() time.Duration {
+	return time.Duration(c.cleanupInterval.Load())
+}
 
-	for key, item := range c.items {
-		if time.Now().UnixNano() > item.Expired && item.Expired > 0 {
-			keys = append(keys, key)
+// deleteExpired runs one GC cycle over the cache and its buckets. With a
+// positive budget it removes at most that many entries, visiting shards
+// round-robin from where the previous cycle stopped so that every shard is
+// eventually cleaned.
+func (c *Cache) deleteExpired(budget int) GCReport {
+	start := time.Now()
+	report := GCReport{Start: start}
+	expired := 0
+	first := 0
+	if budget > 0 {
+		first = int(c.gcCursor.Load()) % len(c.shards)
+	}
+	for n := range c.shards {
+		i := (first + n) % len(c.shards)
+		limit := 0
+		if budget > 0 {
+			limit = budget - expired
 		}
+		removed, scanned, more := c.shards[i].removeExpired(limit)
+		report.Scanned += scanned
+		report.Behind = report.Behind || more
+		if len(removed) > 0 {
+			expired += len(removed)
+			c.stats.expired.Add(uint64(len(removed)))
+			c.evicted(removed)
+		}
+		if budget > 0 && expired >= budget {
+			c.gcCursor.Store(int64(i))
+			for rest := n + 1; rest < len(c.shards) && !report.Behind; rest++ {
+				report.Behind = c.shards[(first+rest)%len(c.shards)].due()
+			}
+			break
+		}
+	}
+	for _, s := range c.shards {
+		s.purgeRemoved()
 	}
-	return
+	c.expireLeases()
+	elapsed := time.Since(start)
+	c.stats.gcRuns.Add(1)
+	c.stats.gcNanos.Add(int64(elapsed))
+	if c.logger != nil {
+		c.logger.Debug("cache gc run", slog.Int("expired", expired), slog.Duration("duration", elapsed))
+	}
+	report.Removed = expired
+	report.Duration = elapsed
+	c.lastGC.Store(&report)
+	if c.onGCComplete != nil {
+		c.onGCComplete(report)
+	}
+
+	for _, b := range c.bucketList() {
+		b.deleteExpired(budget)
+	}
+	return report
 }
 
 func (c *Cache) ClearItems(keys []string) {
-	c.Lock()
-	defer c.Unlock()
+	if c.writable() != nil {
+		return
+	}
+	var cleared []string
+	for s, keys := range c.groupByShard(keys) {
+		removed := s.clearItems(keys)
+		c.deleted(removed)
+		cleared = append(cleared, keysOf(removed)...)
+	}
+	c.invalidate(cleared...)
+}
+
+func (c *Cache) groupByShard(keys []string) map[*shard][]string {
+	if len(c.shards) == 1 {
+		return map[*shard][]string{c.shards[0]: keys}
+	}
+
+	byShard := make(map[*shard][]string)
 	for _, key := range keys {
-		delete(c.items, key)
+		s := c.shardFor(key)
+		byShard[s] = append(byShard[s], key)
+	}
+	return byShard
+}
+
+func (c *Cache) deleted(removed []keyedItem) {
+	c.stats.deletes.Add(uint64(len(removed)))
+	c.evicted(removed)
+}
+
+func keysOf(items []keyedItem) []string {
+	keys := make([]string, len(items))
+	for i, e := range items {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+// Flush removes every entry, holding all shard locks at once so no reader
+// observes a partially flushed cache. Removed entries are passed to the
+// OnEvicted callback.
+func (c *Cache) Flush() error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	if c.flush() > 0 {
+		c.broadcast(Invalidation{Flush: true})
+	}
+	return nil
+}
+
+// flush empties the cache and returns the number of entries removed.
+func (c *Cache) flush() int {
+	c.lockAll()
+	var removed []keyedItem
+	for _, s := range c.shards {
+		removed = append(removed, s.drainLocked()...)
+	}
+	if c.log != nil {
+		c.log.append(logRecord{Op: logFlush})
+	}
+	for _, r := range removed {
+		c.watch.notify(EventDelete, r.key, r.item)
+	}
+	c.unlockAll()
+
+	c.deleted(removed)
+	return len(removed)
+}
+
+// lockAll acquires every shard's write lock in index order.
+func (c *Cache) lockAll() {
+	for _, s := range c.shards {
+		s.Lock()
 	}
 }
 
+func (c *Cache) unlockAll() {
+	for _, s := range c.shards {
+		s.Unlock()
+	}
+}
+
+// Count returns the number of live entries. Expired entries awaiting GC
+// are not counted; see CountAll.
 func (c *Cache) Count() int {
-	c.RLock()
-	n := len(c.items)
-	c.RUnlock()
+	n := 0
+	for _, s := range c.shards {
+		s.RLock()
+		n += len(s.items) - s.expiredCount(s.clock.Now().UnixNano())
+		s.RUnlock()
+	}
 	return n
 }
 
+// CountAll returns the number of entries held, including expired ones not
+// yet removed by GC.
+func (c *Cache) CountAll() int {
+	n := 0
+	for _, s := range c.shards {
+		s.RLock()
+		n += len(s.items)
+		s.RUnlock()
+	}
+	return n
+}
+
+// Rename moves the entry under key to newKey, replacing whatever newKey
+// held, in one step: no reader sees both keys missing.
 func (c *Cache) Rename(key string, newKey string) error {
-	item, ok := c.GetItem(key)
+	return c.rename(key, newKey, false)
+}
+
+// RenameNX is like Rename but fails with ErrKeyExists if newKey holds a
+// live entry.
+func (c *Cache) RenameNX(key string, newKey string) error {
+	return c.rename(key, newKey, true)
+}
+
+func (c *Cache) rename(key, newKey string, nx bool) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	unlock := c.lockKeys(key, newKey)
+	item, ok := c.shardFor(key).live(key)
 	if !ok {
-		return errors.New("key not found")
+		unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+	if key == newKey {
+		unlock()
+		return nil
 	}
-	err := c.Delete(key)
-	if err != nil {
+	dst := c.shardFor(newKey)
+	if _, exists := dst.live(newKey); exists && nx {
+		unlock()
+		return keyError(ErrKeyExists, newKey)
+	}
+	if err := c.storePut(context.Background(), newKey, item); err != nil {
+		unlock()
 		return err
 	}
-	c.Lock()
-	defer c.Unlock()
-	c.items[newKey] = Item{
-		Value:   item.Value,
-		Created: item.Created,
-		Expired: item.Expired,
+	if err := c.storeDelete(context.Background(), key); err != nil {
+		unlock()
+		return err
 	}
+	c.shardFor(key).remove(key)
+	evicted := dst.store(newKey, item)
+	unlock()
+
+	c.overflowed(evicted)
+	c.invalidate(key, newKey)
 	return nil
 }
 
-func (c *Cache) Copy(key, newKey string) error {
-	item, ok := c.GetItem(key)
+type copyOptions struct {
+	resetTTL bool
+	ttl      time.Duration
+	deep     bool
+}
+
+type CopyOption func(*copyOptions)
+
+// CopyWithTTL gives the copy a lifetime of d from now instead of the
+// source's deadline. 0 means the default lifetime.
+func CopyWithTTL(d time.Duration) CopyOption {
+	return func(o *copyOptions) {
+		o.resetTTL = true
+		o.ttl = d
+	}
+}
+
+// CopyDeep stores a deep copy of the value, made with the cache's Codec,
+// instead of sharing it with the source.
+func CopyDeep() CopyOption {
+	return func(o *copyOptions) {
+		o.deep = true
+	}
+}
+
+// Copy stores the entry under key also under newKey, replacing whatever
+// newKey held. The copy keeps the source's deadline, tags and value unless
+// changed with opts.
+func (c *Cache) Copy(key, newKey string, opts ...CopyOption) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	var o copyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	src, ok := c.lookup(key)
 	if !ok {
-		return errors.New("key not found")
+		return keyError(ErrKeyNotFound, key)
+	}
+
+	item := src
+	item.Created = c.clock.Now()
+	item.Tags = append([]string(nil), src.Tags...)
+	item.meta = nil
+	if x, ok := item.Value.(container); ok {
+		// Containers are changed in place, so the copy needs its own.
+		item.Value = x.clone()
+	}
+	if o.deep {
+		value, err := deepCopy(c.codec, c.valueOf(src))
+		if err != nil {
+			return err
+		}
+		item.Value = value
+		item.Compression = Uncompressed
+		c.compress(&item)
+	}
+	if o.resetTTL {
+		item.Expired = c.expiration(newKey, o.ttl)
+		if item.Sliding > 0 {
+			item.Sliding = max(c.lifetime(newKey, o.ttl), 0)
+		}
+	}
+	return c.setItem(newKey, item, setAlways)
+}
+
+// Close stops the cleanup goroutine and releases all entries. If the cache
+// was created WithFlushOnClose, remaining entries are passed to the
+// OnEvicted callback first. Operations on a closed cache fail with
+// ErrCacheClosed.
+func (c *Cache) Close() error {
+	if !c.closed.CompareAndSwap(false, true) {
+		return ErrCacheClosed
+	}
+
+	close(c.stop)
+	if c.bcast != nil {
+		c.bcast.cancel()
+	}
+	c.gcDone.Wait()
+	if c.behind != nil {
+		c.behind.close()
+	}
+
+	for _, b := range c.bucketList() {
+		b.Close()
+	}
+
+	for _, s := range c.shards {
+		removed := s.drain()
+		if c.flushOnClose {
+			c.evicted(removed)
+		}
 	}
+	c.watch.close()
 
-	c.Lock()
-	defer c.Unlock()
-	c.items[key] = Item{
-		Value:   item.Value,
-		Created: item.Created,
-		Expired: item.Expired,
+	if c.log != nil {
+		return c.log.close()
 	}
 	return nil
 }
@@ -0,0 +1,176 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultChangeRetention is how many changes are kept for resuming when
+// Changes is used without WithChangeLog.
+const defaultChangeRetention = 1024
+
+// ChangeEvent is one mutation in the stream returned by Changes. Seq starts
+// at 1 and grows by one per mutation, in the order the mutations were
+// applied.
+type ChangeEvent struct {
+	Event
+	Seq  uint64
+	Time time.Time
+}
+
+type changesOptions struct {
+	from uint64
+}
+
+type ChangesOption func(*changesOptions)
+
+// ChangesFrom resumes the stream at sequence number seq, typically one past
+// the last event the consumer processed.
+func ChangesFrom(seq uint64) ChangesOption {
+	return func(o *changesOptions) { o.from = seq }
+}
+
+// changeLog numbers mutations and keeps the most recent ones in a ring so
+// consumers can catch up and resume.
+type changeLog struct {
+	clock Clock
+
+	mu     sync.Mutex
+	events []ChangeEvent
+	next   uint64
+	wake   chan struct{}
+	closed bool
+}
+
+func newChangeLog(clock Clock, retention int) *changeLog {
+	if retention < 1 {
+		retention = defaultChangeRetention
+	}
+	return &changeLog{
+		clock:  clock,
+		events: make([]ChangeEvent, retention),
+		next:   1,
+		wake:   make(chan struct{}),
+	}
+}
+
+// append records a mutation. It is called with the shard lock held, so
+// consumers see each key's changes in the order they were made.
+func (l *changeLog) append(e Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	seq := l.next
+	l.next++
+	l.events[seq%uint64(len(l.events))] = ChangeEvent{Event: e, Seq: seq, Time: l.clock.Now()}
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// read copies the retained changes from seq on into buf. When there are
+// none yet it returns a channel that is closed on the next append.
+func (l *changeLog) read(seq uint64, buf []ChangeEvent) ([]ChangeEvent, <-chan struct{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if oldest := l.oldest(); seq < oldest {
+		seq = oldest
+	}
+	buf = buf[:0]
+	for ; seq < l.next && len(buf) < cap(buf); seq++ {
+		buf = append(buf, l.events[seq%uint64(len(l.events))])
+	}
+	return buf, l.wake, l.closed
+}
+
+func (l *changeLog) oldest() uint64 {
+	if retention := uint64(len(l.events)); l.next > retention {
+		return l.next - retention
+	}
+	return 1
+}
+
+func (l *changeLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.closed {
+		l.closed = true
+		close(l.wake)
+	}
+}
+
+// Changes returns an ordered stream of every mutation to the cache: sets,
+// deletes, expirations and evictions. Unlike Subscribe it never drops
+// events while the consumer keeps up with the changes retained for
+// resuming, set by WithChangeLog. A consumer that falls further behind, or
+// resumes from a sequence number no longer retained, continues at the
+// oldest retained change; the jump in Seq tells it changes were missed.
+// Without ChangesFrom the stream starts with the next mutation. The
+// channel is closed when ctx is done or the cache is closed.
+//
+// Changes are only recorded once Changes has been called or the cache was
+// created WithChangeLog.
+func (c *Cache) Changes(ctx context.Context, opts ...ChangesOption) <-chan ChangeEvent {
+	var o changesOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ch := make(chan ChangeEvent)
+	log := c.changeLog()
+	if log == nil {
+		close(ch)
+		return ch
+	}
+	if o.from == 0 {
+		log.mu.Lock()
+		o.from = log.next
+		log.mu.Unlock()
+	}
+
+	go func() {
+		defer close(ch)
+		buf := make([]ChangeEvent, 0, watchBuffer)
+		seq := o.from
+		for {
+			events, wake, closed := log.read(seq, buf)
+			for _, e := range events {
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+				seq = e.Seq + 1
+			}
+			if len(events) > 0 {
+				continue
+			}
+			if closed {
+				return
+			}
+			select {
+			case <-wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// changeLog returns the cache's change log, creating it on first use, or
+// nil once the cache is closed.
+func (c *Cache) changeLog() *changeLog {
+	w := c.watch
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	if w.changes.Load() == nil {
+		w.changes.Store(newChangeLog(c.clock, c.changeRetention))
+	}
+	return w.changes.Load()
+}
@@ -0,0 +1,129 @@
+package go_in_memory_cache
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for expiry and background work. The default
+// uses the time package; tests can inject a FakeClock via WithClock.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Clock returns the Clock the cache measures expiry with.
+func (c *Cache) Clock() Clock {
+	return c.clock
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+
+func (t realTimer) Stop() bool { return t.t.Stop() }
+
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+// FakeClock is a Clock that only moves when Advance is called. Timers fire
+// synchronously from Advance once their deadline is reached.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+	// timers holds the active timers only, so fired and stopped ones can
+	// be collected.
+	timers []*fakeTimer
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *FakeClock) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), at: f.now.Add(d), active: true}
+	f.timers = append(f.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d and fires every timer that is due.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if t.at.After(f.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.active = false
+		select {
+		case t.c <- f.now:
+		default:
+		}
+	}
+	clear(f.timers[len(pending):])
+	f.timers = pending
+}
+
+// drop removes t from the active timers. f.mu must be held.
+func (f *FakeClock) drop(t *fakeTimer) {
+	f.timers = slices.DeleteFunc(f.timers, func(other *fakeTimer) bool { return other == t })
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	c      chan time.Time
+	at     time.Time
+	active bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	if wasActive {
+		t.active = false
+		t.clock.drop(t)
+	}
+	return wasActive
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.at = t.clock.now.Add(d)
+	if !wasActive {
+		t.active = true
+		t.clock.timers = append(t.clock.timers, t)
+	}
+	return wasActive
+}
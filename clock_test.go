@@ -0,0 +1,92 @@
+package go_in_memory_cache
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClockExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		sliding bool
+		// reads are the times, from the write, at which key is read.
+		reads   []time.Duration
+		advance time.Duration
+		want    bool
+	}{
+		{name: "before deadline", ttl: time.Minute, advance: 59 * time.Second, want: true},
+		{name: "at deadline", ttl: time.Minute, advance: time.Minute, want: true},
+		{name: "after deadline", ttl: time.Minute, advance: time.Minute + time.Nanosecond, want: false},
+		{name: "no expiration", ttl: NoExpiration, advance: 1000 * time.Hour, want: true},
+		{name: "default lifetime", ttl: 0, advance: time.Hour + time.Second, want: false},
+		{name: "sliding kept alive", ttl: time.Minute, sliding: true, reads: []time.Duration{40 * time.Second, 80 * time.Second}, advance: 130 * time.Second, want: true},
+		{name: "sliding lapsed", ttl: time.Minute, sliding: true, reads: []time.Duration{40 * time.Second}, advance: 101 * time.Second, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(epoch)
+			c := New(time.Hour, 0, WithClock(clock))
+			var err error
+			if tt.sliding {
+				err = c.SetSliding("key", "value", tt.ttl)
+			} else {
+				err = c.Set("key", "value", tt.ttl)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var elapsed time.Duration
+			for _, at := range tt.reads {
+				clock.Advance(at - elapsed)
+				elapsed = at
+				if _, ok := c.Get("key"); !ok {
+					t.Fatalf("Get at %v: miss", at)
+				}
+			}
+			clock.Advance(tt.advance - elapsed)
+			if _, ok := c.Get("key"); ok != tt.want {
+				t.Errorf("Get at %v = %v, want %v", tt.advance, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeClockDropsTimers(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	fired := clock.NewTimer(time.Second)
+	stopped := clock.NewTimer(time.Second)
+	clock.NewTimer(time.Hour)
+
+	if !stopped.Stop() {
+		t.Error("Stop on an active timer reported false")
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-fired.C():
+	default:
+		t.Fatal("due timer did not fire")
+	}
+	if n := len(clock.timers); n != 1 {
+		t.Errorf("clock holds %d timers, want 1", n)
+	}
+
+	if fired.Reset(time.Second) {
+		t.Error("Reset on a fired timer reported it active")
+	}
+	if stopped.Reset(time.Second) {
+		t.Error("Reset on a stopped timer reported it active")
+	}
+	if n := len(clock.timers); n != 3 {
+		t.Errorf("clock holds %d timers after Reset, want 3", n)
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-stopped.C():
+	default:
+		t.Error("reset timer did not fire")
+	}
+}
@@ -0,0 +1,65 @@
+package go_in_memory_cache
+
+// Clone returns a new cache created with the same lifetime, cleanup
+// interval and options as c, holding deep copies of c's live entries made
+// with c's codec. Values the codec cannot encode are shared. The clone is
+// detached from whatever c is connected to: it has no backing store,
+// Loader, broadcaster or append log, no snapshot scheduling, WithWarmup or
+// shutdown hooks. It runs its own GC.
+func (c *Cache) Clone() *Cache {
+	opts := append(append([]Option(nil), c.opts...), func(clone *Cache) {
+		clone.snapshotPath = ""
+		clone.snapshotStore = nil
+		clone.warmup = false
+		clone.shutdownHooks = nil
+		clone.store = nil
+		clone.behind = nil
+		clone.loader = nil
+		clone.bcast = nil
+	})
+	clone := New(c.defaultLifetime, c.interval(), opts...)
+
+	items := c.Export()
+	for key, item := range items {
+		if v, err := deepCopy(c.codec, item.Value); err == nil {
+			item.Value = v
+		}
+		items[key] = item
+	}
+	clone.importItems(items, true, false)
+	return clone
+}
+
+// MergePolicy decides which entry Merge keeps when both caches hold a key.
+type MergePolicy int
+
+const (
+	// MergeKeepExisting keeps the entry already in the receiving cache.
+	MergeKeepExisting MergePolicy = iota
+	// MergeOverwrite replaces it with the other cache's entry.
+	MergeOverwrite
+	// MergeNewest keeps whichever entry was created last.
+	MergeNewest
+)
+
+// Merge copies the live entries of other into c, resolving keys present in
+// both per policy, and returns how many entries were stored. As with
+// Import, entries go through the checks of Set and to the backing store,
+// and those that fail are not stored.
+func (c *Cache) Merge(other *Cache, policy MergePolicy) (int, error) {
+	if err := c.writable(); err != nil {
+		return 0, err
+	}
+
+	stored := 0
+	for key, item := range other.Export() {
+		item.Value = c.copyValue(item.Value)
+		if c.storeEntry(key, item, true, func(cur Item, exists bool) bool {
+			return exists && (policy == MergeKeepExisting ||
+				policy == MergeNewest && !item.Created.After(cur.Created))
+		}) {
+			stored++
+		}
+	}
+	return stored, nil
+}
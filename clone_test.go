@@ -0,0 +1,36 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCloneIsDetached(t *testing.T) {
+	store := &recordingStore{}
+	c := New(0, 0, WithWriteThrough(store), WithLoader(LoaderFunc(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		return "loaded", 0, nil
+	})))
+	if err := c.Set("a", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	store.take()
+
+	clone := c.Clone()
+	defer clone.Close()
+	if v, ok := clone.Get("a"); !ok || v != 1 {
+		t.Errorf("clone Get(a) = %v, %v; want 1, true", v, ok)
+	}
+	if err := clone.Set("b", 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if ops := store.take(); len(ops) != 0 {
+		t.Errorf("writes to the clone reached the store: %q", ops)
+	}
+	if _, err := clone.Fetch(context.Background(), "missing"); err == nil {
+		t.Error("clone used the original's Loader")
+	}
+}
@@ -0,0 +1,97 @@
+package go_in_memory_cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+)
+
+// Codec serializes values, e.g. to copy them in and out of the cache.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	GobCodec  Codec = gobCodec{}
+	JSONCodec Codec = jsonCodec{}
+)
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// GetInto decodes the value stored under key into dst, which must be a
+// pointer. The value is round-tripped through the cache's codec (gob unless
+// set WithCodec), so dst never shares memory with the cached value. A
+// stored []byte is treated as already encoded and decoded directly.
+func (c *Cache) GetInto(key string, dst interface{}) error {
+	value, ok := c.Get(key)
+	if !ok {
+		return keyError(ErrKeyNotFound, key)
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		var err error
+		if data, err = c.codec.Marshal(value); err != nil {
+			return err
+		}
+	}
+	return c.codec.Unmarshal(data, dst)
+}
+
+// copyValue returns a deep copy of v made by round-tripping it through the
+// codec configured WithValueCopy. Without one, or when the codec cannot
+// handle v, v is returned unchanged.
+func (c *Cache) copyValue(v interface{}) interface{} {
+	if x, ok := v.(container); ok {
+		return x.clone()
+	}
+	if c.valueCodec == nil || v == nil {
+		return v
+	}
+
+	copied, err := deepCopy(c.valueCodec, v)
+	if err != nil {
+		return v
+	}
+	return copied
+}
+
+// deepCopy round-trips v through codec into a new value of the same type.
+func deepCopy(codec Codec, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	ptr := reflect.New(reflect.TypeOf(v))
+	if err := codec.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
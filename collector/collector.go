@@ -0,0 +1,83 @@
+// Package collector exports cache statistics as Prometheus metrics.
+package collector
+
+import (
+	cache "go-in-memory-cache"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Collector struct {
+	cache *cache.Cache
+
+	entries    *prometheus.Desc
+	cost       *prometheus.Desc
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	hitRatio   *prometheus.Desc
+	sets       *prometheus.Desc
+	deletes    *prometheus.Desc
+	evictions  *prometheus.Desc
+	expired    *prometheus.Desc
+	gcRuns     *prometheus.Desc
+	gcDuration *prometheus.Desc
+}
+
+// New returns a collector for c. name is attached to every metric as the
+// "cache" label so several caches can share one registry.
+func New(c *cache.Cache, namespace, name string) *Collector {
+	labels := prometheus.Labels{"cache": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", metric), help, nil, labels)
+	}
+
+	return &Collector{
+		cache:      c,
+		entries:    desc("entries", "Number of entries currently stored."),
+		cost:       desc("cost_bytes", "Estimated total weight of stored entries."),
+		hits:       desc("hits_total", "Number of lookups that found a live entry."),
+		misses:     desc("misses_total", "Number of lookups that found nothing."),
+		hitRatio:   desc("hit_ratio", "Hits divided by total lookups."),
+		sets:       desc("sets_total", "Number of entries stored."),
+		deletes:    desc("deletes_total", "Number of entries removed explicitly."),
+		evictions:  desc("evictions_total", "Number of entries evicted by the capacity policy."),
+		expired:    desc("expired_total", "Number of expired entries removed by GC."),
+		gcRuns:     desc("gc_runs_total", "Number of GC cycles."),
+		gcDuration: desc("gc_duration_seconds_total", "Time spent in GC cycles."),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entries
+	ch <- c.cost
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.hitRatio
+	ch <- c.sets
+	ch <- c.deletes
+	ch <- c.evictions
+	ch <- c.expired
+	ch <- c.gcRuns
+	ch <- c.gcDuration
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.cache.Stats()
+
+	var ratio float64
+	if lookups := s.Hits + s.Misses; lookups > 0 {
+		ratio = float64(s.Hits) / float64(lookups)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Entries))
+	ch <- prometheus.MustNewConstMetric(c.cost, prometheus.GaugeValue, float64(s.Cost))
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, ratio)
+	ch <- prometheus.MustNewConstMetric(c.sets, prometheus.CounterValue, float64(s.Sets))
+	ch <- prometheus.MustNewConstMetric(c.deletes, prometheus.CounterValue, float64(s.Deletes))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.expired, prometheus.CounterValue, float64(s.Expired))
+	ch <- prometheus.MustNewConstMetric(c.gcRuns, prometheus.CounterValue, float64(s.GCRuns))
+	ch <- prometheus.MustNewConstMetric(c.gcDuration, prometheus.CounterValue, s.GCDuration.Seconds())
+}
@@ -0,0 +1,122 @@
+package go_in_memory_cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+type Compression uint8
+
+const (
+	Uncompressed Compression = iota
+	CompressedBytes
+	CompressedString
+)
+
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor compresses with gzip at the default level.
+var GzipCompressor Compressor = gzipCompressor{}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// compress replaces a large string or []byte value with its compressed
+// form. Values that do not shrink are left alone.
+func (c *Cache) compress(item *Item) {
+	if c.compressor == nil {
+		return
+	}
+
+	var raw []byte
+	kind := CompressedBytes
+	switch v := item.Value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+		kind = CompressedString
+	default:
+		return
+	}
+	if len(raw) < c.compressMin {
+		return
+	}
+
+	data, err := c.compressor.Compress(raw)
+	if err != nil || len(data) >= len(raw) {
+		return
+	}
+
+	item.Value = data
+	item.Compression = kind
+	item.rawSize = int64(len(raw))
+}
+
+// countCompressed adds item to the shard's compression totals, or with
+// n = -1 takes it out. The caller must hold the write lock.
+func (s *shard) countCompressed(item Item, n int64) {
+	if item.Compression == Uncompressed {
+		return
+	}
+	data, _ := item.Value.([]byte)
+	s.compressed += n
+	s.rawBytes += n * item.rawSize
+	s.compressedBytes += n * int64(len(data))
+}
+
+// rawValue returns the item's value, decompressing it if needed.
+func (c *Cache) rawValue(item Item) interface{} {
+	if item.Compression == Uncompressed || c.compressor == nil {
+		return item.Value
+	}
+
+	data, ok := item.Value.([]byte)
+	if !ok {
+		return item.Value
+	}
+	raw, err := c.compressor.Decompress(data)
+	if err != nil {
+		return item.Value
+	}
+	if item.Compression == CompressedString {
+		return string(raw)
+	}
+	return raw
+}
+
+// valueOf returns the value to hand out to callers: decompressed, and
+// copied when WithValueCopy is set.
+func (c *Cache) valueOf(item Item) interface{} {
+	if item.Compression != Uncompressed {
+		return c.rawValue(item)
+	}
+	if x, ok := item.Value.(container); ok {
+		return c.copyValue(x.view())
+	}
+	return c.copyValue(item.Value)
+}
@@ -0,0 +1,49 @@
+package go_in_memory_cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompressionSurvivesCopies(t *testing.T) {
+	value := strings.Repeat("compressible ", 64)
+	tests := []struct {
+		name string
+		copy func(src *Cache) (*Cache, error)
+	}{
+		{name: "Import", copy: func(src *Cache) (*Cache, error) {
+			dst := New(0, 0, WithCompression(GzipCompressor, 16))
+			return dst, dst.Import(src.Export(), true)
+		}},
+		{name: "Load", copy: func(src *Cache) (*Cache, error) {
+			var buf bytes.Buffer
+			if err := src.Save(&buf); err != nil {
+				return nil, err
+			}
+			dst := New(0, 0, WithCompression(GzipCompressor, 16))
+			return dst, dst.Load(&buf)
+		}},
+		{name: "Clone", copy: func(src *Cache) (*Cache, error) { return src.Clone(), nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := New(0, 0, WithCompression(GzipCompressor, 16))
+			if err := src.Set("a", value, 0); err != nil {
+				t.Fatal(err)
+			}
+			dst, err := tt.copy(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := dst.Get("a"); !ok || v != value {
+				t.Errorf("Get(a) = %.20q, %v; want the original value", v, ok)
+			}
+			got, want := dst.Stats(), src.Stats()
+			if got.Compressed != 1 || got.RawBytes != want.RawBytes || got.CompressedBytes != want.CompressedBytes {
+				t.Errorf("compression stats = %d, %d, %d; want 1, %d, %d",
+					got.Compressed, got.RawBytes, got.CompressedBytes, want.RawBytes, want.CompressedBytes)
+			}
+		})
+	}
+}
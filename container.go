@@ -0,0 +1,151 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// container is a value the data-structure operations (LPush, HSet, ...)
+// modify in place while holding the key's shard lock for writing. Callers
+// never see one: valueOf hands out view, a copy as a plain value, and the
+// operations accept that plain form back, so entries survive Save, Load and
+// the append-only log. Plain forms are registered with gob for that. The
+// embedded lock guards views taken after the shard lock was released, such
+// as in Range.
+type container interface {
+	sync.Locker
+	RLock()
+	RUnlock()
+	view() interface{}
+	clone() container
+	// kind names the container in errors, such as "list".
+	kind() string
+	len() int
+	// weight estimates the memory held, for DefaultWeigher.
+	weight() int64
+}
+
+// containerType describes one kind of container for withContainer and
+// readContainer.
+type containerType[T container] struct {
+	name string
+	make func() T
+	// adopt converts a stored value to T: a T itself, or its plain form.
+	adopt func(v interface{}) (T, bool)
+}
+
+func (t containerType[T]) of(key string, v interface{}) (T, error) {
+	if x, ok := t.adopt(v); ok {
+		return x, nil
+	}
+	var zero T
+	if x, ok := v.(container); ok {
+		return zero, fmt.Errorf("%w: value for %q is a %s, not a %s", ErrTypeMismatch, key, x.kind(), t.name)
+	}
+	return zero, fmt.Errorf("%w: value for %q is %T, not a %s", ErrTypeMismatch, key, v, t.name)
+}
+
+// withContainer runs fn on the T stored under key while holding the key's
+// shard lock for writing. When key holds no live entry, fn gets a new T with
+// the default lifetime if create is set, and ErrKeyNotFound is returned
+// otherwise. If fn reports a change the entry is stored again, keeping its
+// expiry, or deleted if fn left it empty.
+func withContainer[T container](c *Cache, key string, t containerType[T], create bool, fn func(T) (changed bool, err error)) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+	item, ok := s.live(key)
+	var x T
+	switch {
+	case ok:
+		var err error
+		if x, err = t.of(key, c.rawValue(item)); err != nil {
+			s.Unlock()
+			return err
+		}
+		if c.store != nil && c.behind == nil {
+			// Write-through may reject the result, so work on a copy.
+			x = x.clone().(T)
+		}
+	case create:
+		item = c.newItem(key, nil, 0, false)
+		x = t.make()
+	default:
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+
+	x.Lock()
+	changed, err := fn(x)
+	empty := x.len() == 0
+	x.Unlock()
+	if err != nil || !changed {
+		s.Unlock()
+		return err
+	}
+
+	if empty {
+		if err := c.storeDelete(context.Background(), key); err != nil {
+			s.Unlock()
+			return err
+		}
+		removed, present := s.remove(key)
+		s.Unlock()
+		if present {
+			c.deleted([]keyedItem{{key: key, item: removed}})
+			c.invalidate(key)
+		}
+		return nil
+	}
+	item.Value = x
+	item.Compression = Uncompressed
+	if err := c.storePut(context.Background(), key, item); err != nil {
+		s.Unlock()
+		return err
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return nil
+}
+
+// readContainer runs fn on the T stored under key while holding the key's
+// shard lock and the T's own lock for reading. It reports false if key
+// holds no live entry.
+func readContainer[T container](c *Cache, key string, t containerType[T], fn func(T)) (bool, error) {
+	if c.closed.Load() {
+		return false, ErrCacheClosed
+	}
+
+	s := c.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	item, ok := s.live(key)
+	if !ok {
+		return false, nil
+	}
+	x, err := t.of(key, c.rawValue(item))
+	if err != nil {
+		return false, err
+	}
+	s.trackAccess(key)
+	x.RLock()
+	defer x.RUnlock()
+	fn(x)
+	return true, nil
+}
+
+// plain returns v with any container replaced by its view.
+func plain(v interface{}) interface{} {
+	if x, ok := v.(container); ok {
+		return x.view()
+	}
+	return v
+}
@@ -0,0 +1,128 @@
+package go_in_memory_cache
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCopyContainers(t *testing.T) {
+	tests := []struct {
+		name string
+		fill func(c *Cache) error
+		// change modifies the source after the copy.
+		change func(c *Cache) error
+		read   func(c *Cache, key string) (interface{}, error)
+		want   interface{}
+	}{
+		{
+			name: "list",
+			fill: func(c *Cache) error {
+				_, err := c.RPush("src", "a", "b")
+				return err
+			},
+			change: func(c *Cache) error {
+				_, err := c.RPush("src", "c")
+				return err
+			},
+			read: func(c *Cache, key string) (interface{}, error) {
+				return c.LRange(key, 0, -1)
+			},
+			want: []interface{}{"a", "b"},
+		},
+		{
+			name: "hash",
+			fill: func(c *Cache) error {
+				_, err := c.HSet("src", "f", 1)
+				return err
+			},
+			change: func(c *Cache) error {
+				_, err := c.HSet("src", "f", 2)
+				return err
+			},
+			read: func(c *Cache, key string) (interface{}, error) {
+				return c.HGet(key, "f")
+			},
+			want: 1,
+		},
+		{
+			name: "set",
+			fill: func(c *Cache) error {
+				_, err := c.SAdd("src", "a")
+				return err
+			},
+			change: func(c *Cache) error {
+				_, err := c.SAdd("src", "b")
+				return err
+			},
+			read: func(c *Cache, key string) (interface{}, error) {
+				return c.SCard(key)
+			},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(0, 0, WithShards(16))
+			if err := tt.fill(c); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Copy("src", "dst"); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(c); err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.read(c, "dst")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("copy holds %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestContainerConcurrentAccess is meant for -race: pushes to a list race
+// reads of it and of a copy of it.
+func TestContainerConcurrentAccess(t *testing.T) {
+	c := New(0, 0, WithShards(16))
+	if _, err := c.RPush("src", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Copy("src", "dst"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 200; i++ {
+			if _, err := c.RPush("src", i); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for _, key := range []string{"src", "dst"} {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if _, err := c.LRange(key, 0, -1); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n, _ := c.LLen("src"); n != 201 {
+		t.Errorf("LLen(src) = %d, want 201", n)
+	}
+	if n, _ := c.LLen("dst"); n != 1 {
+		t.Errorf("LLen(dst) = %d, want 1", n)
+	}
+}
@@ -0,0 +1,53 @@
+package go_in_memory_cache
+
+import "sort"
+
+// DiffResult lists the keys that differ between two caches, from the
+// first to the second. Each list is sorted.
+type DiffResult struct {
+	// Added keys are live only in the second.
+	Added []string
+	// Removed keys are live only in the first.
+	Removed []string
+	// Changed keys are live in both with different values, compared as
+	// in CompareAndSwap.
+	Changed []string
+}
+
+// Empty reports whether the two sides held the same keys and values.
+func (d DiffResult) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares the live entries of a and b. Each cache is read shard by
+// shard, so concurrent writes may or may not be reflected.
+func Diff(a, b *Cache) DiffResult {
+	return diffItems(a.Export(), b.Export())
+}
+
+// DiffSnapshots compares two snapshots taken with Cache.Snapshot.
+func DiffSnapshots(a, b *Snapshot) DiffResult {
+	return diffItems(a.items, b.items)
+}
+
+func diffItems(a, b map[string]Item) DiffResult {
+	var d DiffResult
+	for key, x := range a {
+		y, ok := b[key]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, key)
+		case x.Compression != y.Compression || !valuesEqual(x.Value, y.Value):
+			d.Changed = append(d.Changed, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			d.Added = append(d.Added, key)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
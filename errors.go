@@ -0,0 +1,43 @@
+package go_in_memory_cache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrKeyExists        = errors.New("key already exists")
+	ErrCacheClosed      = errors.New("cache closed")
+	ErrFrozen           = errors.New("cache is frozen")
+	ErrCapacityExceeded = errors.New("entry exceeds cache capacity")
+	ErrTypeMismatch     = errors.New("value has unexpected type")
+	ErrLocked           = errors.New("key is locked")
+	ErrNotLockHolder    = errors.New("lease not held")
+	ErrTxConflict       = errors.New("transaction conflict")
+	ErrVersionMismatch  = errors.New("version mismatch")
+	ErrRejected         = errors.New("entry rejected by admission policy")
+	ErrSnapshotCorrupt  = errors.New("snapshot corrupt or tampered with")
+	// ErrSnapshotEncrypted is returned when loading an encrypted snapshot
+	// into a cache created without WithSnapshotEncryption.
+	ErrSnapshotEncrypted = errors.New("snapshot is encrypted")
+)
+
+// KeyError annotates one of the sentinel errors with the key it concerns.
+// Use errors.Is to test for the sentinel.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("%v: %q", e.Err, e.Key)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+func keyError(err error, key string) error {
+	return &KeyError{Key: key, Err: err}
+}
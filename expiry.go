@@ -0,0 +1,165 @@
+package go_in_memory_cache
+
+import "container/heap"
+
+type expiryEntry struct {
+	key   string
+	at    int64
+	index int
+}
+
+// expiryHeap orders a shard's expiring keys by deadline so GC only visits
+// entries that are actually due.
+type expiryHeap []*expiryEntry
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool { return h[i].at < h[j].at }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	e := x.(*expiryEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+// setExpiry schedules key to expire at the given UnixNano deadline, or
+// unschedules it when at is 0. The caller must hold the write lock.
+func (s *shard) setExpiry(key string, at int64) {
+	e, ok := s.expiries[key]
+	switch {
+	case at == 0 && ok:
+		s.clearExpiry(key)
+	case at == 0:
+	case ok:
+		e.at = at
+		heap.Fix(&s.expiryQueue, e.index)
+	default:
+		if s.expiries == nil {
+			s.expiries = make(map[string]*expiryEntry)
+		}
+		e = &expiryEntry{key: key, at: at}
+		s.expiries[key] = e
+		heap.Push(&s.expiryQueue, e)
+	}
+}
+
+func (s *shard) clearExpiry(key string) {
+	if e, ok := s.expiries[key]; ok {
+		heap.Remove(&s.expiryQueue, e.index)
+		delete(s.expiries, key)
+	}
+}
+
+func (s *shard) hasExpired(now int64) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return len(s.expiryQueue) > 0 && now > s.expiryQueue[0].at
+}
+
+// reap removes key if it holds an entry whose deadline passed more than
+// the grace period ago, as GC would. It must be called without the shard
+// lock held.
+func (c *Cache) reap(s *shard, key string) {
+	s.Lock()
+	item, ok := s.items[key]
+	if !ok || item.Expired == 0 || s.clock.Now().UnixNano()-s.grace <= item.Expired {
+		s.Unlock()
+		return
+	}
+	s.expire(key)
+	s.Unlock()
+
+	c.stats.expired.Add(1)
+	c.evicted([]keyedItem{{key: key, item: item}})
+}
+
+// due reports whether removeExpired has anything to remove.
+func (s *shard) due() bool {
+	return s.hasExpired(s.clock.Now().UnixNano() - s.grace)
+}
+
+// removeExpired removes entries whose deadline passed more than the grace
+// period ago, at most limit of them if limit is positive. scanned counts
+// the expiry records visited and more reports whether the limit stopped it
+// early.
+func (s *shard) removeExpired(limit int) (removed []keyedItem, scanned int, more bool) {
+	now := s.clock.Now().UnixNano() - s.grace
+	if !s.hasExpired(now) {
+		return nil, 0, false
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for len(s.expiryQueue) > 0 && now > s.expiryQueue[0].at {
+		if limit > 0 && len(removed) >= limit {
+			return removed, scanned, true
+		}
+		scanned++
+		key := s.expiryQueue[0].key
+		item, ok := s.expire(key)
+		if !ok {
+			s.clearExpiry(key)
+			continue
+		}
+		removed = append(removed, keyedItem{key: key, item: item})
+	}
+	return
+}
+
+// expiredCount returns how many entries are past their deadline at now,
+// visiting only the part of the heap that is. The caller must hold the
+// read lock.
+func (s *shard) expiredCount(now int64) int {
+	if len(s.expiryQueue) == 0 || s.expiryQueue[0].at >= now {
+		return 0
+	}
+	n := 0
+	stack := []int{0}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if i >= len(s.expiryQueue) || s.expiryQueue[i].at >= now {
+			continue
+		}
+		n++
+		stack = append(stack, 2*i+1, 2*i+2)
+	}
+	return n
+}
+
+// expiringBy returns the live entries whose deadline is at or before limit.
+// Subtrees of the heap whose root is past limit are skipped.
+func (s *shard) expiringBy(now, limit int64) []*expiryEntry {
+	s.RLock()
+	defer s.RUnlock()
+
+	var found []*expiryEntry
+	stack := []int{0}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if i >= len(s.expiryQueue) || s.expiryQueue[i].at > limit {
+			continue
+		}
+		if e := s.expiryQueue[i]; e.at >= now {
+			found = append(found, &expiryEntry{key: e.key, at: e.at})
+		}
+		stack = append(stack, 2*i+1, 2*i+2)
+	}
+	return found
+}
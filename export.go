@@ -0,0 +1,152 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Export returns a copy of every live entry, like Items.
+func (c *Cache) Export() map[string]Item {
+	items := c.Items()
+	for key, item := range items {
+		item.meta = nil
+		items[key] = item
+	}
+	return items
+}
+
+// Import stores items, skipping those that have already expired. Keys that
+// hold a live entry are replaced only if overwrite is set. Each entry goes
+// through the same capacity and admission checks as Set and is written to
+// the backing store; entries that fail them are skipped.
+func (c *Cache) Import(items map[string]Item, overwrite bool) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+	c.importItems(items, overwrite, true)
+	return nil
+}
+
+// ImportLocal is Import changing only this cache: entries are neither
+// written to the backing store nor broadcast, as with Load. Use it to apply
+// changes that came from the store or from a peer.
+func (c *Cache) ImportLocal(items map[string]Item, overwrite bool) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+	c.importItems(items, overwrite, false)
+	return nil
+}
+
+// importItems is Import, writing to the backing store only with propagate.
+func (c *Cache) importItems(items map[string]Item, overwrite, propagate bool) {
+	now := c.clock.Now().UnixNano()
+	for key, item := range items {
+		if item.Expired > 0 && now > item.Expired {
+			continue
+		}
+		c.storeEntry(key, item, propagate, func(_ Item, exists bool) bool {
+			return exists && !overwrite
+		})
+	}
+}
+
+// storeEntry stores an entry taken from a snapshot or another cache, with
+// the capacity and admission checks of Set, unless keep, given the live
+// entry under key, says to leave that one in place. With propagate the
+// entry is also written to the backing store and broadcast. Uncompressed values are
+// compressed as Set would. It reports whether the entry was stored.
+func (c *Cache) storeEntry(key string, item Item, propagate bool, keep func(cur Item, exists bool) bool) bool {
+	item.meta = nil
+	if item.Compression == Uncompressed {
+		c.compress(&item)
+	}
+	s := c.shardFor(key)
+	s.Lock()
+	if cur, ok := s.live(key); keep(cur, ok) {
+		s.Unlock()
+		return false
+	}
+	cost := s.weigh(key, item.Value)
+	if s.maxCost > 0 && cost > s.maxCost {
+		s.Unlock()
+		return false
+	}
+	if !s.admit(key, cost) {
+		s.Unlock()
+		c.stats.rejected.Add(1)
+		return false
+	}
+	if propagate && c.storePut(context.Background(), key, item) != nil {
+		s.Unlock()
+		return false
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	if propagate {
+		c.invalidate(key)
+	}
+	return true
+}
+
+type jsonEntry struct {
+	Value    interface{}   `json:"value"`
+	Created  time.Time     `json:"created"`
+	Expires  *time.Time    `json:"expires,omitempty"`
+	Sliding  time.Duration `json:"sliding,omitempty"`
+	Tags     []string      `json:"tags,omitempty"`
+	Priority Priority      `json:"priority,omitempty"`
+	Negative bool          `json:"negative,omitempty"`
+}
+
+// MarshalJSON encodes the live entries as an object keyed by cache key.
+func (c *Cache) MarshalJSON() ([]byte, error) {
+	entries := make(map[string]jsonEntry)
+	for key, item := range c.Export() {
+		e := jsonEntry{
+			Value:    item.Value,
+			Created:  item.Created,
+			Sliding:  item.Sliding,
+			Tags:     item.Tags,
+			Priority: item.Priority,
+			Negative: item.Negative,
+		}
+		if item.Expired > 0 {
+			t := time.Unix(0, item.Expired)
+			e.Expires = &t
+		}
+		entries[key] = e
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON imports entries encoded by MarshalJSON into a cache made
+// with New, replacing existing entries under the same keys. Values come
+// back as the types encoding/json decodes into interface{}.
+func (c *Cache) UnmarshalJSON(data []byte) error {
+	var entries map[string]jsonEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	items := make(map[string]Item, len(entries))
+	for key, e := range entries {
+		item := Item{
+			Value:    e.Value,
+			Created:  e.Created,
+			Sliding:  e.Sliding,
+			Tags:     e.Tags,
+			Priority: e.Priority,
+			Negative: e.Negative,
+		}
+		if e.Expires != nil {
+			item.Expired = e.Expires.UnixNano()
+		}
+		items[key] = item
+	}
+	return c.Import(items, true)
+}
@@ -0,0 +1,37 @@
+package go_in_memory_cache
+
+// Freeze makes the cache read-only until Unfreeze. Calls that change
+// entries fail with ErrFrozen, or do nothing when they have no error to
+// report, such as Pop and CompareAndSwap. Reads carry on as usual,
+// including filling a miss through the Loader, GetOrSet or GetOrCompute,
+// and so does expiry. Writes already under way when Freeze is called may
+// still complete.
+func (c *Cache) Freeze() {
+	c.frozen.Store(true)
+}
+
+// Unfreeze lifts a Freeze.
+func (c *Cache) Unfreeze() {
+	c.frozen.Store(false)
+}
+
+// Frozen reports whether the cache is frozen. A bucket is frozen with its
+// parent.
+func (c *Cache) Frozen() bool {
+	return c.frozen.Load() || c.parent != nil && c.parent.Frozen()
+}
+
+// writable returns the error a mutation fails with: ErrCacheClosed or
+// ErrFrozen, nil if it may go ahead.
+func (c *Cache) writable() error {
+	if c.closed.Load() {
+		return ErrCacheClosed
+	}
+	if c.frozen.Load() {
+		return ErrFrozen
+	}
+	if c.parent != nil {
+		return c.parent.writable()
+	}
+	return nil
+}
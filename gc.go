@@ -0,0 +1,81 @@
+package go_in_memory_cache
+
+import (
+	"errors"
+	"time"
+)
+
+// GCReport describes one GC cycle.
+type GCReport struct {
+	Start time.Time
+	// Scanned counts expiry records visited and Removed the entries
+	// removed; they differ when a record outlived its entry.
+	Scanned  int
+	Removed  int
+	Duration time.Duration
+	// Behind is set when WithGCBudget stopped the cycle before every
+	// expired entry was removed.
+	Behind bool
+}
+
+// DeleteExpired removes all expired entries now, without waiting for the
+// next GC cycle.
+func (c *Cache) DeleteExpired() error {
+	if c.closed.Load() {
+		return ErrCacheClosed
+	}
+	c.deleteExpired(0)
+	return nil
+}
+
+// PauseGC stops the cleanup goroutine from removing expired entries until
+// ResumeGC is called. Expired entries stay invisible to readers meanwhile.
+func (c *Cache) PauseGC() {
+	c.gcPaused.Store(true)
+}
+
+func (c *Cache) ResumeGC() {
+	c.gcPaused.Store(false)
+}
+
+// adapt adjusts the cleanup interval after a cycle when WithAdaptiveGC is
+// set: it halves the interval while cycles fall behind or expire a large
+// share of the cache, and doubles it while they find nothing to do.
+func (c *Cache) adapt(r GCReport) {
+	if c.gcMax <= 0 {
+		return
+	}
+	d := c.interval()
+	switch {
+	case r.Behind || r.Removed*10 > c.CountAll():
+		d /= 2
+	case r.Removed == 0:
+		d *= 2
+	default:
+		return
+	}
+	c.cleanupInterval.Store(int64(min(max(d, c.gcMin), c.gcMax)))
+}
+
+// SetCleanupInterval changes how often GC runs, starting the cleanup
+// goroutine if the cache was created without one. The new interval applies
+// immediately.
+func (c *Cache) SetCleanupInterval(d time.Duration) error {
+	if d <= 0 {
+		return errors.New("cleanup interval must be positive")
+	}
+	if c.closed.Load() {
+		return ErrCacheClosed
+	}
+
+	c.cleanupInterval.Store(int64(d))
+	if c.gcStarted.Load() {
+		select {
+		case c.gcReset <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	c.StartGC()
+	return nil
+}
@@ -0,0 +1,33 @@
+package go_in_memory_cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartGC(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		starts   int
+		running  bool
+	}{
+		{name: "no interval", interval: 0, starts: 1, running: false},
+		{name: "once", interval: time.Minute, starts: 1, running: true},
+		{name: "repeated", interval: time.Minute, starts: 3, running: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(0, tt.interval, WithClock(NewFakeClock(epoch)))
+			for range tt.starts {
+				c.StartGC()
+			}
+			if got := c.gcStarted.Load(); got != tt.running {
+				t.Fatalf("GC running = %v, want %v", got, tt.running)
+			}
+			if err := c.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
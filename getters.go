@@ -0,0 +1,49 @@
+package go_in_memory_cache
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetAs returns the value stored under key asserted to T. It fails with
+// ErrKeyNotFound if key holds no live entry and ErrTypeMismatch if the value
+// is not a T.
+func GetAs[T any](c *Cache, key string) (T, error) {
+	var zero T
+
+	value, ok := c.Get(key)
+	if !ok {
+		return zero, keyError(ErrKeyNotFound, key)
+	}
+
+	v, ok := value.(T)
+	if !ok {
+		want := reflect.TypeOf((*T)(nil)).Elem()
+		return zero, fmt.Errorf("%w: value for %q is %T, want %v", ErrTypeMismatch, key, value, want)
+	}
+	return v, nil
+}
+
+func (c *Cache) GetString(key string) (string, error) {
+	return GetAs[string](c, key)
+}
+
+func (c *Cache) GetInt(key string) (int, error) {
+	return GetAs[int](c, key)
+}
+
+func (c *Cache) GetInt64(key string) (int64, error) {
+	return GetAs[int64](c, key)
+}
+
+func (c *Cache) GetFloat64(key string) (float64, error) {
+	return GetAs[float64](c, key)
+}
+
+func (c *Cache) GetBytes(key string) ([]byte, error) {
+	return GetAs[[]byte](c, key)
+}
+
+func (c *Cache) GetBool(key string) (bool, error) {
+	return GetAs[bool](c, key)
+}
@@ -0,0 +1,305 @@
+// Package group shares the work of filling caches across a fleet of
+// instances, in the manner of groupcache. Every key is owned by one peer,
+// chosen by consistent hashing; on a miss the other peers ask the owner
+// for the value instead of loading it themselves, so a fleet loads each
+// key from the origin once rather than once per instance. The service is
+// defined in grouppb/group.proto.
+//
+// Each instance creates a Group around its cache, serves it with Register
+// and adds the other instances as peers:
+//
+//	g := group.New("10.0.0.1:9000", c, loader)
+//	g.Register(srv)
+//	g.AddPeer("10.0.0.2:9000", conn)
+//
+// Keys fetched from their owner often are copied into a small local hot
+// cache for a short while, so a single hot key does not funnel every
+// instance's traffic to its owner.
+package group
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/group/grouppb"
+	"go-in-memory-cache/internal/singleflight"
+	"go-in-memory-cache/ring"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc -I grouppb --go_out=grouppb --go_opt=paths=source_relative --go-grpc_out=grouppb --go-grpc_opt=paths=source_relative grouppb/group.proto
+
+type config struct {
+	codec        cache.Codec
+	replicas     int
+	hotThreshold int
+	hotTTL       time.Duration
+	hotEntries   int
+}
+
+type Option func(*config)
+
+// WithCodec selects how values are encoded on the wire. All peers must use
+// the same codec. It defaults to cache.GobCodec, which requires custom
+// value types to be registered with gob.Register.
+func WithCodec(codec cache.Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+// WithReplicas sets how many virtual nodes each peer gets on the hash
+// ring. All peers must use the same value.
+func WithReplicas(n int) Option {
+	return func(c *config) {
+		c.replicas = n
+	}
+}
+
+// WithHotKeys copies a key into the hot cache once it has been fetched from
+// its owner threshold times, keeping the copy for at most ttl and never
+// past the owner's expiry. At most entries keys are kept. The defaults are
+// 10 fetches, one minute and 1024 keys; a threshold below 1 turns hot-key
+// replication off.
+func WithHotKeys(threshold int, ttl time.Duration, entries int) Option {
+	return func(c *config) {
+		c.hotThreshold = threshold
+		c.hotTTL = ttl
+		c.hotEntries = entries
+	}
+}
+
+// maxHotCounts bounds how many keys fetch counts are kept for. The counts
+// start over once it is reached, so only keys fetched often in a short
+// span become hot.
+const maxHotCounts = 10000
+
+// Group fills a cache through its peers. It is safe for concurrent use,
+// including while peers are added and removed.
+type Group struct {
+	self   string
+	cache  *cache.Cache
+	loader cache.Loader
+	cfg    config
+
+	mu    sync.RWMutex
+	hash  *ring.Hash
+	peers map[string]grouppb.GroupClient
+
+	hot       *cache.Cache
+	hotMu     sync.Mutex
+	hotCounts map[string]int
+
+	loads   singleflight.Group[loaded]
+	fetches singleflight.Group[loaded]
+}
+
+// New returns a Group for the instance named self, which must be the name
+// the other peers add it under. Keys this instance owns are loaded with
+// loader and stored in c; loader's ttl is handled as in cache.Loader.
+// Expiry, including that of hot copies, is measured with c's Clock.
+func New(self string, c *cache.Cache, loader cache.Loader, opts ...Option) *Group {
+	cfg := config{codec: cache.GobCodec, hotThreshold: 10, hotTTL: time.Minute, hotEntries: 1024}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	g := &Group{
+		self:   self,
+		cache:  c,
+		loader: loader,
+		cfg:    cfg,
+		hash:   ring.NewHash(cfg.replicas),
+		peers:  make(map[string]grouppb.GroupClient),
+	}
+	g.hash.Add(self)
+	if cfg.hotThreshold > 0 {
+		g.hot = cache.New(cfg.hotTTL, cfg.hotTTL, cache.WithMaxEntries(cfg.hotEntries), cache.WithClock(c.Clock()))
+		g.hotCounts = make(map[string]int)
+	}
+	return g
+}
+
+// Register serves g to its peers on s.
+func (g *Group) Register(s grpc.ServiceRegistrar) {
+	grouppb.RegisterGroupServer(s, &server{g: g})
+}
+
+// AddPeer makes the instance behind conn a peer under name, replacing any
+// peer of the same name. Closing conn is up to the caller.
+func (g *Group) AddPeer(name string, conn grpc.ClientConnInterface) {
+	if name == g.self {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.peers[name]; !ok {
+		g.hash.Add(name)
+	}
+	g.peers[name] = grouppb.NewGroupClient(conn)
+}
+
+// RemovePeer stops asking the named peer for keys; the keys it owned move
+// to the remaining peers.
+func (g *Group) RemovePeer(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.peers[name]; ok {
+		g.hash.Remove(name)
+		delete(g.peers, name)
+	}
+}
+
+// Close releases the hot cache. It does not close c or any peer
+// connection.
+func (g *Group) Close() error {
+	if g.hot == nil {
+		return nil
+	}
+	return g.hot.Close()
+}
+
+// owner returns the client for the peer owning key, or nil if this
+// instance owns it.
+func (g *Group) owner(key string) grouppb.GroupClient {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	name, _ := g.hash.Get(key)
+	return g.peers[name]
+}
+
+// Get returns the value for key. On a miss the key's owner loads it, this
+// instance if it owns key and the owning peer otherwise. If the owner
+// cannot be reached the key is loaded locally instead. A key the loader
+// reports as missing yields an error wrapping cache.ErrKeyNotFound.
+func (g *Group) Get(ctx context.Context, key string) (interface{}, error) {
+	if value, ok := g.cache.Get(key); ok {
+		return value, nil
+	}
+	if g.hot != nil {
+		if value, ok := g.hot.Get(key); ok {
+			return value, nil
+		}
+	}
+
+	peer := g.owner(key)
+	if peer == nil {
+		value, _, err := g.load(ctx, key)
+		return value, err
+	}
+	fetched, err := g.fetches.Do(key, func() (loaded, error) {
+		value, err := g.fetch(ctx, peer, key)
+		return loaded{value: value}, err
+	})
+	if status.Code(err) == codes.Unavailable {
+		value, _, err := g.load(ctx, key)
+		return value, err
+	}
+	return fetched.value, err
+}
+
+// loaded is a value shared by a flight, with its expiry in Unix
+// nanoseconds.
+type loaded struct {
+	value   interface{}
+	expires int64
+}
+
+// load returns key from the local cache, loading and storing it on a miss,
+// along with its expiry in Unix nanoseconds. Concurrent loads of a key,
+// local or on behalf of peers, share a single call to the loader.
+func (g *Group) load(ctx context.Context, key string) (interface{}, int64, error) {
+	l, err := g.loads.Do(key, func() (loaded, error) {
+		if item, ok := g.cache.GetItem(key); ok {
+			return loaded{value: item.Value, expires: item.Expired}, nil
+		}
+		if _, state := g.cache.Lookup(key); state == cache.StateNegative {
+			return loaded{}, &cache.KeyError{Key: key, Err: cache.ErrKeyNotFound}
+		}
+		value, ttl, err := g.loader.Load(ctx, key)
+		if err != nil {
+			return loaded{}, err
+		}
+		if err := g.cache.Set(key, value, ttl); err != nil {
+			// Too large to cache or the cache is closed; serve it uncached.
+			return loaded{value: value}, nil
+		}
+		var expires int64
+		if d, ok := g.cache.TTL(key); ok && d != cache.NoExpiration {
+			expires = g.cache.Clock().Now().Add(d).UnixNano()
+		}
+		return loaded{value: value, expires: expires}, nil
+	})
+	return l.value, l.expires, err
+}
+
+func (g *Group) fetch(ctx context.Context, peer grouppb.GroupClient, key string) (interface{}, error) {
+	resp, err := peer.Get(ctx, &grouppb.GetRequest{Key: key})
+	if status.Code(err) == codes.NotFound {
+		return nil, &cache.KeyError{Key: key, Err: cache.ErrKeyNotFound}
+	}
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := g.cfg.codec.Unmarshal(resp.Value, &value); err != nil {
+		return nil, err
+	}
+	g.recordFetch(key, value, resp.ExpiresUnixNano)
+	return value, nil
+}
+
+// recordFetch counts a fetch of key from its owner and copies value into
+// the hot cache once key has become hot.
+func (g *Group) recordFetch(key string, value interface{}, expires int64) {
+	if g.hot == nil {
+		return
+	}
+	g.hotMu.Lock()
+	if len(g.hotCounts) >= maxHotCounts {
+		clear(g.hotCounts)
+	}
+	g.hotCounts[key]++
+	hot := g.hotCounts[key] >= g.cfg.hotThreshold
+	if hot {
+		delete(g.hotCounts, key)
+	}
+	g.hotMu.Unlock()
+	if !hot {
+		return
+	}
+
+	deadline := g.cache.Clock().Now().Add(g.cfg.hotTTL)
+	if expires > 0 && expires < deadline.UnixNano() {
+		deadline = time.Unix(0, expires)
+	}
+	g.hot.SetWithDeadline(key, value, deadline)
+}
+
+type server struct {
+	grouppb.UnimplementedGroupServer
+	g *Group
+}
+
+// Get serves a peer's request for a key this instance owns. The key is
+// loaded locally even if this instance's view of the ring disagrees, so
+// requests never bounce between peers.
+func (s *server) Get(ctx context.Context, req *grouppb.GetRequest) (*grouppb.GetResponse, error) {
+	value, expires, err := s.g.load(ctx, req.Key)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, status.Errorf(codes.NotFound, "key %q not found", req.Key)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+	data, err := s.g.cfg.codec.Marshal(&value)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode value of %q: %v", req.Key, err)
+	}
+	return &grouppb.GetResponse{Value: data, ExpiresUnixNano: expires}, nil
+}
@@ -0,0 +1,179 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/group/grouppb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// instance is one member of a test fleet.
+type instance struct {
+	g     *Group
+	c     *cache.Cache
+	loads atomic.Int32
+	srv   *grpc.Server
+	conn  *grpc.ClientConn
+}
+
+// newFleet starts a Group for each name, each serving over an in-memory
+// listener and knowing all the others as peers. Every instance loads key
+// as "<key> from <name>", except keys starting with "missing".
+func newFleet(t *testing.T, names []string, opts ...Option) map[string]*instance {
+	t.Helper()
+	fleet := make(map[string]*instance)
+	for _, name := range names {
+		in := &instance{c: cache.New(0, 0, cache.WithClock(cache.NewFakeClock(epoch)))}
+		in.g = New(name, in.c, cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+			in.loads.Add(1)
+			if len(key) >= 7 && key[:7] == "missing" {
+				return nil, 0, cache.ErrKeyNotFound
+			}
+			return key + " from " + name, time.Minute, nil
+		}), opts...)
+		t.Cleanup(func() { in.g.Close() })
+
+		l := bufconn.Listen(1 << 20)
+		in.srv = grpc.NewServer()
+		in.g.Register(in.srv)
+		go in.srv.Serve(l)
+		t.Cleanup(in.srv.Stop)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		in.conn = conn
+		fleet[name] = in
+	}
+	for _, in := range fleet {
+		for name, peer := range fleet {
+			in.g.AddPeer(name, peer.conn)
+		}
+	}
+	return fleet
+}
+
+// keyOwnedBy returns a key with prefix that name owns in g's ring.
+func keyOwnedBy(t *testing.T, g *Group, prefix, name string) string {
+	t.Helper()
+	for i := range 1000 {
+		key := fmt.Sprint(prefix, i)
+		if owner, _ := g.hash.Get(key); owner == name {
+			return key
+		}
+	}
+	t.Fatalf("no key owned by %s", name)
+	return ""
+}
+
+func TestGetFromOwner(t *testing.T) {
+	fleet := newFleet(t, []string{"a", "b"}, WithHotKeys(0, 0, 0))
+	a, b := fleet["a"], fleet["b"]
+	key := keyOwnedBy(t, b.g, "key", "a")
+
+	for range 3 {
+		v, err := b.g.Get(context.Background(), key)
+		if err != nil || v != key+" from a" {
+			t.Fatalf("Get = %v, %v; want the owner's value", v, err)
+		}
+	}
+	if n := a.loads.Load(); n != 1 {
+		t.Errorf("owner loaded %d times, want 1", n)
+	}
+	if n := b.loads.Load(); n != 0 {
+		t.Errorf("non-owner loaded %d times, want 0", n)
+	}
+	if _, ok := a.c.Get(key); !ok {
+		t.Error("owner did not cache the value")
+	}
+	if _, ok := b.c.Get(key); ok {
+		t.Error("non-owner cached the value")
+	}
+}
+
+func TestGetOwnKey(t *testing.T) {
+	fleet := newFleet(t, []string{"a", "b"})
+	a := fleet["a"]
+	key := keyOwnedBy(t, a.g, "key", "a")
+
+	if v, err := a.g.Get(context.Background(), key); err != nil || v != key+" from a" {
+		t.Fatalf("Get = %v, %v", v, err)
+	}
+	if n := fleet["b"].loads.Load(); n != 0 {
+		t.Errorf("peer loaded %d times for a key it does not own", n)
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	fleet := newFleet(t, []string{"a", "b"})
+	b := fleet["b"]
+	key := keyOwnedBy(t, b.g, "missing", "a")
+
+	if _, err := b.g.Get(context.Background(), key); !errors.Is(err, cache.ErrKeyNotFound) {
+		t.Errorf("Get = %v, want %v", err, cache.ErrKeyNotFound)
+	}
+}
+
+func TestGetOwnerUnavailable(t *testing.T) {
+	fleet := newFleet(t, []string{"a", "b"})
+	a, b := fleet["a"], fleet["b"]
+	key := keyOwnedBy(t, b.g, "key", "a")
+	a.srv.Stop()
+
+	if v, err := b.g.Get(context.Background(), key); err != nil || v != key+" from b" {
+		t.Errorf("Get = %v, %v; want the value loaded locally", v, err)
+	}
+}
+
+func TestHotKeys(t *testing.T) {
+	fleet := newFleet(t, []string{"a", "b"}, WithHotKeys(2, time.Minute, 10))
+	a, b := fleet["a"], fleet["b"]
+	key := keyOwnedBy(t, b.g, "key", "a")
+
+	for range 2 {
+		if _, err := b.g.Get(context.Background(), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The owner is gone, but the hot copy still serves the owner's value
+	// instead of a local load.
+	a.srv.Stop()
+	if v, err := b.g.Get(context.Background(), key); err != nil || v != key+" from a" {
+		t.Errorf("Get = %v, %v; want the hot copy", v, err)
+	}
+	if n := b.loads.Load(); n != 0 {
+		t.Errorf("non-owner loaded %d times, want 0", n)
+	}
+}
+
+func TestServerReportsExpiry(t *testing.T) {
+	fleet := newFleet(t, []string{"a"})
+	a := fleet["a"]
+	s := &server{g: a.g}
+
+	for _, name := range []string{"load", "hit"} {
+		resp, err := s.Get(context.Background(), &grouppb.GetRequest{Key: "key"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := epoch.Add(time.Minute).UnixNano(); resp.ExpiresUnixNano != want {
+			t.Errorf("%s: expiry %v, want %v", name, time.Unix(0, resp.ExpiresUnixNano).UTC(), time.Unix(0, want).UTC())
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package grpcserver serves a cache over gRPC so that other services can use
+// it as a lightweight sidecar. The service is defined in cachepb/cache.proto.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/grpcserver/cachepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc -I cachepb --go_out=cachepb --go_opt=paths=source_relative --go-grpc_out=cachepb --go-grpc_opt=paths=source_relative cachepb/cache.proto
+
+type Server struct {
+	cachepb.UnimplementedCacheServer
+	cache *cache.Cache
+}
+
+func New(c *cache.Cache) *Server {
+	return &Server{cache: c}
+}
+
+// Register serves c on s.
+func Register(s grpc.ServiceRegistrar, c *cache.Cache) {
+	cachepb.RegisterCacheServer(s, New(c))
+}
+
+func (s *Server) Get(ctx context.Context, req *cachepb.GetRequest) (*cachepb.GetResponse, error) {
+	item, ok := s.cache.GetItem(req.Key)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "key %q not found", req.Key)
+	}
+
+	var value []byte
+	switch v := item.Value.(type) {
+	case []byte:
+		value = v
+	case string:
+		value = []byte(v)
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "key %q holds a %T, not bytes", req.Key, item.Value)
+	}
+
+	ttl := int64(-1)
+	if item.Expired > 0 {
+		ttl = max(time.Unix(0, item.Expired).Sub(s.cache.Clock().Now()).Milliseconds(), 0)
+	}
+	return &cachepb.GetResponse{Value: value, TtlMs: ttl}, nil
+}
+
+func (s *Server) Set(ctx context.Context, req *cachepb.SetRequest) (*cachepb.SetResponse, error) {
+	duration := time.Duration(req.TtlMs) * time.Millisecond
+	if req.TtlMs < 0 {
+		duration = cache.NoExpiration
+	}
+
+	var err error
+	switch req.Mode {
+	case cachepb.SetMode_SET_MODE_IF_ABSENT:
+		err = s.cache.Add(req.Key, req.Value, duration)
+	case cachepb.SetMode_SET_MODE_IF_PRESENT:
+		err = s.cache.Replace(req.Key, req.Value, duration)
+	default:
+		err = s.cache.SetCtx(ctx, req.Key, req.Value, duration)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &cachepb.SetResponse{}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *cachepb.DeleteRequest) (*cachepb.DeleteResponse, error) {
+	if err := s.cache.DeleteCtx(ctx, req.Key); err != nil {
+		return nil, toStatus(err)
+	}
+	return &cachepb.DeleteResponse{}, nil
+}
+
+func (s *Server) Watch(req *cachepb.WatchRequest, stream grpc.ServerStreamingServer[cachepb.Event]) error {
+	var events <-chan cache.Event
+	var stop func()
+	if req.Prefix {
+		events, stop = s.cache.WatchPrefix(req.Key)
+	} else {
+		events, stop = s.cache.Watch(req.Key)
+	}
+	defer stop()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, cache.ErrCacheClosed.Error())
+			}
+			msg := &cachepb.Event{Key: ev.Key}
+			switch ev.Type {
+			case cache.EventSet:
+				msg.Type = cachepb.Event_TYPE_SET
+			case cache.EventDelete, cache.EventEvict:
+				msg.Type = cachepb.Event_TYPE_DELETE
+			case cache.EventExpire:
+				msg.Type = cachepb.Event_TYPE_EXPIRE
+			}
+			switch v := ev.Value.(type) {
+			case []byte:
+				msg.Value = v
+			case string:
+				msg.Value = []byte(v)
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *Server) Stats(req *cachepb.StatsRequest, stream grpc.ServerStreamingServer[cachepb.StatsResponse]) error {
+	interval := time.Duration(req.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		st := s.cache.Stats()
+		err := stream.Send(&cachepb.StatsResponse{
+			Hits:         st.Hits,
+			Misses:       st.Misses,
+			Sets:         st.Sets,
+			Deletes:      st.Deletes,
+			Evictions:    st.Evictions,
+			Expired:      st.Expired,
+			Entries:      int64(st.Entries),
+			Cost:         st.Cost,
+			MaxCost:      st.MaxCost,
+			GcRuns:       st.GCRuns,
+			GcDurationNs: int64(st.GCDuration),
+		})
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, cache.ErrKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, cache.ErrKeyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, cache.ErrCapacityExceeded), errors.Is(err, cache.ErrRejected):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, cache.ErrFrozen):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, cache.ErrCacheClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
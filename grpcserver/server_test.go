@@ -0,0 +1,185 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/grpcserver/cachepb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(c *cache.Cache) error
+		code  codes.Code
+		value string
+		ttl   int64
+	}{
+		{name: "missing", code: codes.NotFound},
+		{
+			name:  "bytes with ttl",
+			setup: func(c *cache.Cache) error { return c.Set("key", []byte("v"), time.Minute) },
+			value: "v",
+			ttl:   time.Minute.Milliseconds(),
+		},
+		{
+			name:  "string without expiry",
+			setup: func(c *cache.Cache) error { return c.Set("key", "v", cache.NoExpiration) },
+			value: "v",
+			ttl:   -1,
+		},
+		{
+			name:  "not bytes",
+			setup: func(c *cache.Cache) error { return c.Set("key", 1, 0) },
+			code:  codes.FailedPrecondition,
+		},
+		{
+			name:  "negative entry",
+			setup: func(c *cache.Cache) error { return c.SetNegative("key", time.Minute) },
+			code:  codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.New(0, 0, cache.WithClock(cache.NewFakeClock(epoch)))
+			if tt.setup != nil {
+				if err := tt.setup(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			resp, err := New(c).Get(context.Background(), &cachepb.GetRequest{Key: "key"})
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("Get = %v, want code %v", err, tt.code)
+			}
+			if err != nil {
+				return
+			}
+			if string(resp.Value) != tt.value || resp.TtlMs != tt.ttl {
+				t.Errorf("Get = %q, ttl %d; want %q, ttl %d", resp.Value, resp.TtlMs, tt.value, tt.ttl)
+			}
+		})
+	}
+}
+
+func TestGetDoesNotSlide(t *testing.T) {
+	clock := cache.NewFakeClock(epoch)
+	c := cache.New(0, 0, cache.WithClock(clock))
+	if err := c.SetSliding("key", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(40 * time.Second)
+
+	resp, err := New(c).Get(context.Background(), &cachepb.GetRequest{Key: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TtlMs != time.Minute.Milliseconds() {
+		t.Errorf("TtlMs = %d, want the slid lifetime of 1m", resp.TtlMs)
+	}
+	if hits := c.Stats().Hits; hits != 1 {
+		t.Errorf("Get counted %d hits, want 1", hits)
+	}
+}
+
+func TestSetAndDelete(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(c *cache.Cache)
+		call  func(s *Server) error
+		code  codes.Code
+	}{
+		{
+			name: "set",
+			call: func(s *Server) error {
+				_, err := s.Set(context.Background(), &cachepb.SetRequest{Key: "key", Value: []byte("v")})
+				return err
+			},
+		},
+		{
+			name: "set if absent over a live key",
+			call: func(s *Server) error {
+				_, err := s.Set(context.Background(), &cachepb.SetRequest{Key: "old", Value: []byte("v"), Mode: cachepb.SetMode_SET_MODE_IF_ABSENT})
+				return err
+			},
+			code: codes.AlreadyExists,
+		},
+		{
+			name: "set if present on a missing key",
+			call: func(s *Server) error {
+				_, err := s.Set(context.Background(), &cachepb.SetRequest{Key: "key", Value: []byte("v"), Mode: cachepb.SetMode_SET_MODE_IF_PRESENT})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "delete missing",
+			call: func(s *Server) error {
+				_, err := s.Delete(context.Background(), &cachepb.DeleteRequest{Key: "key"})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:  "set frozen",
+			setup: func(c *cache.Cache) { c.Freeze() },
+			call: func(s *Server) error {
+				_, err := s.Set(context.Background(), &cachepb.SetRequest{Key: "key", Value: []byte("v")})
+				return err
+			},
+			code: codes.FailedPrecondition,
+		},
+		{
+			name:  "delete closed",
+			setup: func(c *cache.Cache) { c.Close() },
+			call: func(s *Server) error {
+				_, err := s.Delete(context.Background(), &cachepb.DeleteRequest{Key: "old"})
+				return err
+			},
+			code: codes.Unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.New(0, 0)
+			if err := c.Set("old", []byte("v"), 0); err != nil {
+				t.Fatal(err)
+			}
+			if tt.setup != nil {
+				tt.setup(c)
+			}
+			if code := status.Code(tt.call(New(c))); code != tt.code {
+				t.Errorf("code = %v, want %v", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{cache.ErrKeyNotFound, codes.NotFound},
+		{&cache.KeyError{Key: "k", Err: cache.ErrKeyExists}, codes.AlreadyExists},
+		{cache.ErrCapacityExceeded, codes.ResourceExhausted},
+		{cache.ErrRejected, codes.ResourceExhausted},
+		{cache.ErrFrozen, codes.FailedPrecondition},
+		{cache.ErrCacheClosed, codes.Unavailable},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{fmt.Errorf("store: %w", context.Canceled), codes.Canceled},
+		{fmt.Errorf("disk on fire"), codes.Internal},
+	}
+	for _, tt := range tests {
+		if code := status.Code(toStatus(tt.err)); code != tt.code {
+			t.Errorf("toStatus(%v) = %v, want %v", tt.err, code, tt.code)
+		}
+	}
+}
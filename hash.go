@@ -0,0 +1,175 @@
+package go_in_memory_cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"maps"
+	"sync"
+)
+
+func init() {
+	gob.Register(map[string]interface{}(nil))
+}
+
+// hashValue is the container behind the hash operations: a map of fields
+// to values. Its plain form is map[string]interface{}.
+type hashValue struct {
+	sync.RWMutex
+	fields map[string]interface{}
+	bytes  int64
+}
+
+var hashType = containerType[*hashValue]{
+	name: "hash",
+	make: func() *hashValue { return &hashValue{fields: make(map[string]interface{})} },
+	adopt: func(v interface{}) (*hashValue, bool) {
+		switch v := v.(type) {
+		case *hashValue:
+			return v, true
+		case map[string]interface{}:
+			h := &hashValue{fields: make(map[string]interface{}, len(v))}
+			for field, value := range v {
+				h.set(field, value)
+			}
+			return h, true
+		}
+		return nil, false
+	},
+}
+
+// set stores value under field and reports whether the field is new.
+func (h *hashValue) set(field string, value interface{}) bool {
+	old, ok := h.fields[field]
+	if ok {
+		h.bytes -= DefaultWeigher(field, old)
+	}
+	h.fields[field] = value
+	h.bytes += DefaultWeigher(field, value)
+	return !ok
+}
+
+func (h *hashValue) del(field string) bool {
+	old, ok := h.fields[field]
+	if ok {
+		delete(h.fields, field)
+		h.bytes -= DefaultWeigher(field, old)
+	}
+	return ok
+}
+
+func (h *hashValue) view() interface{} {
+	h.RLock()
+	defer h.RUnlock()
+	return maps.Clone(h.fields)
+}
+
+func (h *hashValue) clone() container {
+	h.RLock()
+	defer h.RUnlock()
+	return &hashValue{fields: maps.Clone(h.fields), bytes: h.bytes}
+}
+
+func (*hashValue) kind() string {
+	return "hash"
+}
+
+func (h *hashValue) len() int {
+	return len(h.fields)
+}
+
+func (h *hashValue) weight() int64 {
+	return h.bytes
+}
+
+// HSet stores value under field of the hash under key and reports whether
+// the field is new. Other fields are left alone, so writers updating
+// different fields do not overwrite each other. A missing key is created as
+// a hash with the default lifetime; an existing one keeps its expiry. A
+// key holding a map[string]interface{} is treated as a hash. Any other
+// value fails with ErrTypeMismatch.
+func (c *Cache) HSet(key, field string, value interface{}) (bool, error) {
+	var added bool
+	err := withContainer(c, key, hashType, true, func(h *hashValue) (bool, error) {
+		added = h.set(field, c.copyValue(value))
+		return true, nil
+	})
+	return added, err
+}
+
+// HGet returns the value of field in the hash under key. It fails with
+// ErrKeyNotFound if the key or the field is missing.
+func (c *Cache) HGet(key, field string) (interface{}, error) {
+	var value interface{}
+	var ok bool
+	_, err := readContainer(c, key, hashType, func(h *hashValue) {
+		value, ok = h.fields[field]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, keyError(ErrKeyNotFound, key)
+	}
+	return c.copyValue(value), nil
+}
+
+// HDel removes fields from the hash under key and returns how many were
+// present. A hash left empty is deleted.
+func (c *Cache) HDel(key string, fields ...string) (int, error) {
+	var n int
+	err := withContainer(c, key, hashType, false, func(h *hashValue) (bool, error) {
+		for _, field := range fields {
+			if h.del(field) {
+				n++
+			}
+		}
+		return n > 0, nil
+	})
+	if errors.Is(err, ErrKeyNotFound) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// HGetAll returns a copy of every field of the hash under key, an empty map
+// if it is missing.
+func (c *Cache) HGetAll(key string) (map[string]interface{}, error) {
+	all := make(map[string]interface{})
+	_, err := readContainer(c, key, hashType, func(h *hashValue) {
+		for field, value := range h.fields {
+			all[field] = c.copyValue(value)
+		}
+	})
+	return all, err
+}
+
+// HLen returns the number of fields in the hash under key, 0 if it is
+// missing.
+func (c *Cache) HLen(key string) (int, error) {
+	var n int
+	_, err := readContainer(c, key, hashType, func(h *hashValue) {
+		n = len(h.fields)
+	})
+	return n, err
+}
+
+// HIncrBy adds delta to the integer in field of the hash under key and
+// returns the new value. A missing field counts as int64(0), and a missing
+// key is created as for HSet. The field keeps its integer type.
+func (c *Cache) HIncrBy(key, field string, delta int64) (int64, error) {
+	var result int64
+	err := withContainer(c, key, hashType, true, func(h *hashValue) (bool, error) {
+		var old interface{} = int64(0)
+		if v, ok := h.fields[field]; ok {
+			old = v
+		}
+		v, n, err := addInt(key, old, delta)
+		if err != nil {
+			return false, err
+		}
+		h.set(field, v)
+		result = n
+		return true, nil
+	})
+	return result, err
+}
@@ -0,0 +1,189 @@
+package go_in_memory_cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sync"
+)
+
+func init() {
+	gob.Register(HyperLogLog(nil))
+}
+
+// hllPrecision is the number of hash bits picking a register. 2^14
+// registers give a standard error of about 0.8%.
+const (
+	hllPrecision = 14
+	hllRegisters = 1 << hllPrecision
+)
+
+// HyperLogLog is the plain form of a PFAdd entry as returned by Get: one
+// byte per register. It is only meaningful to PFAdd, PFCount and PFMerge.
+type HyperLogLog []byte
+
+// hllValue is the container behind the HyperLogLog operations.
+type hllValue struct {
+	sync.RWMutex
+	registers []byte
+}
+
+var hllType = containerType[*hllValue]{
+	name: "HyperLogLog",
+	make: func() *hllValue { return &hllValue{registers: make([]byte, hllRegisters)} },
+	adopt: func(v interface{}) (*hllValue, bool) {
+		switch v := v.(type) {
+		case *hllValue:
+			return v, true
+		case HyperLogLog:
+			if len(v) == hllRegisters {
+				return &hllValue{registers: bytes.Clone(v)}, true
+			}
+		}
+		return nil, false
+	},
+}
+
+// hllHash hashes element independently of the process, so registers stay
+// valid after Save and Load.
+func hllHash(element string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(element))
+	x := h.Sum64()
+	// FNV spreads short inputs poorly across the high bits; finish with the
+	// splitmix64 mixer.
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// add records element and reports whether a register changed.
+func (h *hllValue) add(element string) bool {
+	x := hllHash(element)
+	i := x >> (64 - hllPrecision)
+	rho := byte(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rho > h.registers[i] {
+		h.registers[i] = rho
+		return true
+	}
+	return false
+}
+
+// mergeRegisters raises each register in dst to its counterpart in src and
+// reports whether any changed.
+func mergeRegisters(dst, src []byte) bool {
+	changed := false
+	for i, r := range src {
+		if r > dst[i] {
+			dst[i] = r
+			changed = true
+		}
+	}
+	return changed
+}
+
+// hllEstimate returns the approximate number of distinct elements
+// recorded in registers.
+func hllEstimate(registers []byte) uint64 {
+	m := float64(hllRegisters)
+	var sum float64
+	zeros := 0
+	for _, r := range registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		// Small cardinalities: linear counting is more accurate.
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(e + 0.5)
+}
+
+func (h *hllValue) view() interface{} {
+	h.RLock()
+	defer h.RUnlock()
+	return HyperLogLog(bytes.Clone(h.registers))
+}
+
+func (h *hllValue) clone() container {
+	h.RLock()
+	defer h.RUnlock()
+	return &hllValue{registers: bytes.Clone(h.registers)}
+}
+
+func (*hllValue) kind() string {
+	return "HyperLogLog"
+}
+
+func (h *hllValue) len() int {
+	return len(h.registers)
+}
+
+func (h *hllValue) weight() int64 {
+	return int64(len(h.registers))
+}
+
+// PFAdd records elements in the HyperLogLog under key and reports whether
+// its estimate may have changed. A HyperLogLog takes 16 KiB however many
+// elements it has seen. A missing key is created with the default lifetime;
+// an existing one keeps its expiry. Any value other than a HyperLogLog
+// fails with ErrTypeMismatch.
+func (c *Cache) PFAdd(key string, elements ...string) (bool, error) {
+	var changed bool
+	err := withContainer(c, key, hllType, true, func(h *hllValue) (bool, error) {
+		for _, e := range elements {
+			if h.add(e) {
+				changed = true
+			}
+		}
+		// A new key is stored even without elements.
+		return true, nil
+	})
+	return changed, err
+}
+
+// PFCount returns the approximate number of distinct elements added to the
+// HyperLogLogs under keys, counting elements seen by several once. Missing
+// keys count as empty. Each key is read in turn, not all at once.
+func (c *Cache) PFCount(keys ...string) (uint64, error) {
+	registers, err := c.unionRegisters(keys)
+	if err != nil {
+		return 0, err
+	}
+	return hllEstimate(registers), nil
+}
+
+// PFMerge stores the union of the HyperLogLogs under sources in dest, so
+// that dest counts every element any of them, or dest itself, has seen. A
+// missing dest is created as for PFAdd.
+func (c *Cache) PFMerge(dest string, sources ...string) error {
+	registers, err := c.unionRegisters(sources)
+	if err != nil {
+		return err
+	}
+	return withContainer(c, dest, hllType, true, func(h *hllValue) (bool, error) {
+		mergeRegisters(h.registers, registers)
+		return true, nil
+	})
+}
+
+func (c *Cache) unionRegisters(keys []string) ([]byte, error) {
+	registers := make([]byte, hllRegisters)
+	for _, key := range keys {
+		_, err := readContainer(c, key, hllType, func(h *hllValue) {
+			mergeRegisters(registers, h.registers)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return registers, nil
+}
@@ -0,0 +1,121 @@
+// Package netserver runs the connection lifecycle shared by the cache's
+// line protocol servers: tracking listeners and connections, and closing
+// them all on shutdown.
+package netserver
+
+import (
+	"net"
+	"sync"
+)
+
+type Server struct {
+	// handle serves one connection. The connection is closed once it
+	// returns.
+	handle    func(conn net.Conn)
+	errClosed error
+
+	mu       sync.Mutex
+	closed   bool
+	listener map[net.Listener]struct{}
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// New returns a server passing each accepted connection to handle. Serve
+// and Close fail with errClosed once the server is closed.
+func New(handle func(conn net.Conn), errClosed error) *Server {
+	return &Server{
+		handle:    handle,
+		errClosed: errClosed,
+		listener:  make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address addr and serves requests until
+// the server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until it fails or the server is closed,
+// in which case it returns errClosed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return s.errClosed
+	}
+	s.listener[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listener, l)
+		s.mu.Unlock()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return s.errClosed
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return s.errClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops all listeners, closes open connections and waits for their
+// handlers to return.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return s.errClosed
+	}
+	s.closed = true
+	for l := range s.listener {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+		s.wg.Done()
+	}()
+
+	s.handle(conn)
+}
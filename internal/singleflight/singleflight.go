@@ -0,0 +1,85 @@
+// Package singleflight coalesces concurrent calls for the same key, as
+// used by the cache's loaders and by group.
+package singleflight
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// errGoexit is returned to waiters when the leading call exits its
+// goroutine with runtime.Goexit.
+var errGoexit = errors.New("singleflight: call exited its goroutine")
+
+// PanicError is returned to the callers waiting on a call that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("singleflight: call panicked: %v\n\n%s", e.Value, e.Stack)
+}
+
+type call[T any] struct {
+	wg    sync.WaitGroup
+	value T
+	err   error
+}
+
+// Group coalesces concurrent calls for the same key so that only one of
+// them runs fn while the rest wait for its result. The zero Group is ready
+// to use.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+// Do runs fn for key unless a call for key is already running, in which
+// case it waits for that call and returns its result. If fn panics, the
+// caller that ran it panics with the same value and every waiter gets a
+// *PanicError.
+func (g *Group[T]) Do(key string, fn func() (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err
+	}
+	c := &call[T]{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	returned := false
+	defer func() {
+		var recovered interface{}
+		if !returned {
+			// recover returns nil only when fn called runtime.Goexit.
+			recovered = recover()
+			if recovered == nil {
+				c.err = errGoexit
+			} else {
+				c.err = &PanicError{Value: recovered, Stack: debug.Stack()}
+			}
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	c.value, c.err = fn()
+	returned = true
+	return c.value, c.err
+}
@@ -0,0 +1,126 @@
+package singleflight
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startCallers starts n callers of key on g and returns once fn is
+// running and the others have had time to join its flight.
+func startCallers(g *Group[string], key string, n int, fn func() (string, error)) chan error {
+	started := make(chan struct{})
+	results := make(chan error, n)
+	var once sync.Once
+	for range n {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					results <- errors.New("leader panicked")
+				}
+			}()
+			_, err := g.Do(key, func() (string, error) {
+				once.Do(func() { close(started) })
+				return fn()
+			})
+			results <- err
+		}()
+	}
+	<-started
+	time.Sleep(10 * time.Millisecond)
+	return results
+}
+
+func TestDoSharesCalls(t *testing.T) {
+	var g Group[string]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func() (string, error) {
+		calls.Add(1)
+		<-release
+		return "v", nil
+	}
+
+	const n = 8
+	results := startCallers(&g, "key", n, fn)
+	close(release)
+	for range n {
+		if err := <-results; err != nil {
+			t.Errorf("Do = %v", err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("fn ran %d times, want 1", got)
+	}
+
+	if v, err := g.Do("key", func() (string, error) { return "again", nil }); v != "again" || err != nil {
+		t.Errorf("Do after the flight = %q, %v; want again, nil", v, err)
+	}
+}
+
+func TestDoPanic(t *testing.T) {
+	var g Group[string]
+	release := make(chan struct{})
+	fn := func() (string, error) {
+		<-release
+		panic("boom")
+	}
+
+	const n = 4
+	results := startCallers(&g, "key", n, fn)
+	close(release)
+	var panicked, failed int
+	for range n {
+		err := <-results
+		var perr *PanicError
+		switch {
+		case errors.As(err, &perr) && perr.Value == "boom":
+			failed++
+		case err != nil && err.Error() == "leader panicked":
+			panicked++
+		default:
+			t.Errorf("caller got %v", err)
+		}
+	}
+	if panicked != 1 || failed != n-1 {
+		t.Errorf("%d callers panicked and %d got PanicError, want 1 and %d", panicked, failed, n-1)
+	}
+}
+
+func TestDoGoexit(t *testing.T) {
+	var g Group[string]
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.Do("key", func() (string, error) {
+			<-release
+			runtime.Goexit()
+			return "", nil
+		})
+	}()
+	for {
+		g.mu.Lock()
+		n := len(g.calls)
+		g.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := g.Do("key", func() (string, error) { return "", nil })
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-done
+	if err := <-errc; !errors.Is(err, errGoexit) {
+		t.Errorf("waiter got %v, want %v", err, errGoexit)
+	}
+}
@@ -0,0 +1,97 @@
+package go_in_memory_cache
+
+// Keys returns the keys of all live entries in no particular order.
+func (c *Cache) Keys() []string {
+	var keys []string
+	c.Range(func(key string, _ Item) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Items returns a copy of all live entries.
+func (c *Cache) Items() map[string]Item {
+	items := make(map[string]Item)
+	c.Range(func(key string, item Item) bool {
+		items[key] = item
+		return true
+	})
+	return items
+}
+
+// Range calls fn for every live entry until fn returns false. Each shard is
+// copied under its read lock and fn runs without any lock held, so fn may
+// call back into the cache. Entries written after their shard was copied
+// may or may not be visited.
+func (c *Cache) Range(fn func(key string, item Item) bool) {
+	if c.closed.Load() {
+		return
+	}
+
+	for _, s := range c.shards {
+		for _, e := range s.snapshot() {
+			e.item.Value = c.valueOf(e.item)
+			e.item.Compression = Uncompressed
+			if !fn(e.key, e.item) {
+				return
+			}
+		}
+	}
+}
+
+// Oldest returns the live entry created longest ago.
+func (c *Cache) Oldest() (string, *Item, bool) {
+	return c.extreme(func(a, b Item) bool { return a.Created.Before(b.Created) })
+}
+
+// Newest returns the most recently created live entry.
+func (c *Cache) Newest() (string, *Item, bool) {
+	return c.extreme(func(a, b Item) bool { return a.Created.After(b.Created) })
+}
+
+// extreme returns the live entry that no other entry is better than.
+func (c *Cache) extreme(better func(a, b Item) bool) (string, *Item, bool) {
+	if c.closed.Load() {
+		return "", nil, false
+	}
+
+	var best keyedItem
+	found := false
+	for _, s := range c.shards {
+		s.RLock()
+		now := s.clock.Now().UnixNano()
+		for key, item := range s.items {
+			if item.Expired > 0 && now > item.Expired {
+				continue
+			}
+			if !found || better(item, best.item) {
+				best, found = keyedItem{key: key, item: item}, true
+			}
+		}
+		s.RUnlock()
+	}
+	if !found {
+		return "", nil, false
+	}
+
+	best.item.Value = c.valueOf(best.item)
+	best.item.Compression = Uncompressed
+	return best.key, &best.item, true
+}
+
+// snapshot returns the shard's live entries.
+func (s *shard) snapshot() []keyedItem {
+	s.RLock()
+	defer s.RUnlock()
+
+	now := s.clock.Now().UnixNano()
+	entries := make([]keyedItem, 0, len(s.items))
+	for key, item := range s.items {
+		if item.Expired > 0 && now > item.Expired {
+			continue
+		}
+		entries = append(entries, keyedItem{key: key, item: item})
+	}
+	return entries
+}
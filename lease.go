@@ -0,0 +1,130 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// LeaseToken identifies one acquisition of a key's lease.
+type LeaseToken uint64
+
+type lease struct {
+	token    LeaseToken
+	expires  int64
+	released chan struct{}
+}
+
+// Lock takes an exclusive lease on key for ttl, for coordinating work such
+// as rebuilding an expensive value. It fails with ErrLocked while another
+// unexpired lease is held. Leases are independent of entries: locking a key
+// does not create, block or protect its value.
+func (c *Cache) Lock(key string, ttl time.Duration) (LeaseToken, error) {
+	token, _, err := c.tryLock(key, ttl)
+	return token, err
+}
+
+// LockWait is like Lock but waits for the current lease to be released or
+// to expire, until ctx is done.
+func (c *Cache) LockWait(ctx context.Context, key string, ttl time.Duration) (LeaseToken, error) {
+	for {
+		token, held, err := c.tryLock(key, ttl)
+		if err == nil || held == nil {
+			return token, err
+		}
+
+		timer := c.clock.NewTimer(time.Duration(held.expires - c.clock.Now().UnixNano()))
+		select {
+		case <-held.released:
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// tryLock acquires key's lease, or returns the lease currently held.
+func (c *Cache) tryLock(key string, ttl time.Duration) (LeaseToken, *lease, error) {
+	if c.closed.Load() {
+		return 0, nil, ErrCacheClosed
+	}
+	if ttl <= 0 {
+		ttl = c.lifetime(key, 0)
+	}
+
+	now := c.clock.Now().UnixNano()
+	c.leaseMu.Lock()
+	defer c.leaseMu.Unlock()
+	if l, ok := c.leases[key]; ok {
+		if now <= l.expires {
+			return 0, l, keyError(ErrLocked, key)
+		}
+		close(l.released)
+	}
+	if c.leases == nil {
+		c.leases = make(map[string]*lease)
+	}
+	c.leaseSeq++
+	l := &lease{token: LeaseToken(c.leaseSeq), released: make(chan struct{})}
+	if ttl > 0 {
+		l.expires = now + int64(ttl)
+	} else {
+		l.expires = math.MaxInt64
+	}
+	c.leases[key] = l
+	return l.token, nil, nil
+}
+
+// Extend renews the lease identified by token for another ttl from now. As
+// with Lock, a ttl of 0 or less means the key's default lifetime.
+func (c *Cache) Extend(key string, token LeaseToken, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.lifetime(key, 0)
+	}
+	now := c.clock.Now().UnixNano()
+	c.leaseMu.Lock()
+	defer c.leaseMu.Unlock()
+	l, ok := c.leases[key]
+	if !ok || l.token != token || now > l.expires {
+		return keyError(ErrNotLockHolder, key)
+	}
+	if ttl > 0 {
+		l.expires = now + int64(ttl)
+	} else {
+		l.expires = math.MaxInt64
+	}
+	return nil
+}
+
+// Unlock releases the lease identified by token. It fails with
+// ErrNotLockHolder if the lease already expired or was taken over.
+func (c *Cache) Unlock(key string, token LeaseToken) error {
+	now := c.clock.Now().UnixNano()
+	c.leaseMu.Lock()
+	defer c.leaseMu.Unlock()
+	l, ok := c.leases[key]
+	if !ok || l.token != token {
+		return keyError(ErrNotLockHolder, key)
+	}
+	delete(c.leases, key)
+	close(l.released)
+	if now > l.expires {
+		return keyError(ErrNotLockHolder, key)
+	}
+	return nil
+}
+
+// expireLeases drops leases that expired without being unlocked.
+func (c *Cache) expireLeases() {
+	now := c.clock.Now().UnixNano()
+	c.leaseMu.Lock()
+	defer c.leaseMu.Unlock()
+	for key, l := range c.leases {
+		if now > l.expires {
+			delete(c.leases, key)
+			close(l.released)
+		}
+	}
+}
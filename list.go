@@ -0,0 +1,217 @@
+package go_in_memory_cache
+
+import (
+	"encoding/gob"
+	"sync"
+)
+
+func init() {
+	gob.Register([]interface{}(nil))
+}
+
+// listValue is the container behind the list operations: a double-ended queue
+// in a ring buffer. Its plain form is []interface{}.
+type listValue struct {
+	sync.RWMutex
+	buf   []interface{}
+	head  int
+	n     int
+	bytes int64
+}
+
+var listType = containerType[*listValue]{
+	name: "list",
+	make: func() *listValue { return &listValue{} },
+	adopt: func(v interface{}) (*listValue, bool) {
+		switch v := v.(type) {
+		case *listValue:
+			return v, true
+		case []interface{}:
+			l := &listValue{}
+			for _, e := range v {
+				l.pushBack(e)
+			}
+			return l, true
+		}
+		return nil, false
+	},
+}
+
+func (l *listValue) at(i int) interface{} {
+	return l.buf[(l.head+i)%len(l.buf)]
+}
+
+func (l *listValue) grow() {
+	if l.n < len(l.buf) {
+		return
+	}
+	buf := make([]interface{}, max(2*len(l.buf), 8))
+	for i := 0; i < l.n; i++ {
+		buf[i] = l.at(i)
+	}
+	l.buf, l.head = buf, 0
+}
+
+func (l *listValue) pushBack(v interface{}) {
+	l.grow()
+	l.buf[(l.head+l.n)%len(l.buf)] = v
+	l.n++
+	l.bytes += DefaultWeigher("", v)
+}
+
+func (l *listValue) pushFront(v interface{}) {
+	l.grow()
+	l.head = (l.head + len(l.buf) - 1) % len(l.buf)
+	l.buf[l.head] = v
+	l.n++
+	l.bytes += DefaultWeigher("", v)
+}
+
+func (l *listValue) popFront() interface{} {
+	v := l.buf[l.head]
+	l.buf[l.head] = nil
+	l.head = (l.head + 1) % len(l.buf)
+	l.n--
+	l.bytes -= DefaultWeigher("", v)
+	return v
+}
+
+func (l *listValue) popBack() interface{} {
+	i := (l.head + l.n - 1) % len(l.buf)
+	v := l.buf[i]
+	l.buf[i] = nil
+	l.n--
+	l.bytes -= DefaultWeigher("", v)
+	return v
+}
+
+// slice returns elements start through stop, inclusive. Negative indexes
+// count from the end, -1 being the last element; out-of-range indexes are
+// clamped.
+func (l *listValue) slice(start, stop int) []interface{} {
+	if start < 0 {
+		start = max(l.n+start, 0)
+	}
+	if stop < 0 {
+		stop = l.n + stop
+	}
+	stop = min(stop, l.n-1)
+	if start > stop {
+		return nil
+	}
+	out := make([]interface{}, 0, stop-start+1)
+	for i := start; i <= stop; i++ {
+		out = append(out, l.at(i))
+	}
+	return out
+}
+
+func (l *listValue) view() interface{} {
+	l.RLock()
+	defer l.RUnlock()
+	return l.slice(0, -1)
+}
+
+func (l *listValue) clone() container {
+	l.RLock()
+	defer l.RUnlock()
+	c := &listValue{buf: make([]interface{}, len(l.buf)), n: l.n, bytes: l.bytes}
+	for i := 0; i < l.n; i++ {
+		c.buf[i] = l.at(i)
+	}
+	return c
+}
+
+func (*listValue) kind() string {
+	return "list"
+}
+
+func (l *listValue) len() int {
+	return l.n
+}
+
+func (l *listValue) weight() int64 {
+	return l.bytes
+}
+
+// LPush inserts values at the head of the list under key, one after the
+// other, so the last value ends up first, and returns the list's new
+// length. A missing key is created as a list with the default lifetime; an
+// existing one keeps its expiry. A key holding a []interface{}, such as a
+// list reloaded by Load, is treated as a list. Any other value fails with
+// ErrTypeMismatch.
+func (c *Cache) LPush(key string, values ...interface{}) (int, error) {
+	return c.push(key, values, (*listValue).pushFront)
+}
+
+// RPush appends values to the tail of the list under key, as LPush
+// otherwise.
+func (c *Cache) RPush(key string, values ...interface{}) (int, error) {
+	return c.push(key, values, (*listValue).pushBack)
+}
+
+func (c *Cache) push(key string, values []interface{}, add func(*listValue, interface{})) (int, error) {
+	if len(values) == 0 {
+		return c.LLen(key)
+	}
+	var n int
+	err := withContainer(c, key, listType, true, func(l *listValue) (bool, error) {
+		for _, v := range values {
+			add(l, c.copyValue(v))
+		}
+		n = l.n
+		return true, nil
+	})
+	if err == nil {
+		c.queues.signal(key)
+	}
+	return n, err
+}
+
+// LPop removes and returns the first element of the list under key. A list
+// left empty is deleted. It fails with ErrKeyNotFound if key holds no
+// list.
+func (c *Cache) LPop(key string) (interface{}, error) {
+	return c.pop(key, (*listValue).popFront)
+}
+
+// RPop removes and returns the last element of the list under key, as LPop
+// otherwise.
+func (c *Cache) RPop(key string) (interface{}, error) {
+	return c.pop(key, (*listValue).popBack)
+}
+
+func (c *Cache) pop(key string, take func(*listValue) interface{}) (interface{}, error) {
+	var v interface{}
+	err := withContainer(c, key, listType, false, func(l *listValue) (bool, error) {
+		if l.n == 0 {
+			return false, keyError(ErrKeyNotFound, key)
+		}
+		v = take(l)
+		return true, nil
+	})
+	return v, err
+}
+
+// LRange returns the elements of the list under key from start through
+// stop, inclusive. Negative indexes count from the end, so LRange(key, 0,
+// -1) returns the whole list. A missing key yields an empty result.
+func (c *Cache) LRange(key string, start, stop int) ([]interface{}, error) {
+	var out []interface{}
+	_, err := readContainer(c, key, listType, func(l *listValue) {
+		out = l.slice(start, stop)
+	})
+	for i, v := range out {
+		out[i] = c.copyValue(v)
+	}
+	return out, err
+}
+
+// LLen returns the length of the list under key, 0 if it is missing.
+func (c *Cache) LLen(key string) (int, error) {
+	var n int
+	_, err := readContainer(c, key, listType, func(l *listValue) {
+		n = l.n
+	})
+	return n, err
+}
@@ -0,0 +1,148 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Loader fetches values missing from the cache. The returned ttl is used as
+// the entry's lifetime, with 0 meaning the cache's default lifetime and
+// NoExpiration meaning none.
+type Loader interface {
+	Load(ctx context.Context, key string) (value interface{}, ttl time.Duration, err error)
+}
+
+// LoaderFunc adapts a function to the Loader interface.
+type LoaderFunc func(ctx context.Context, key string) (interface{}, time.Duration, error)
+
+func (f LoaderFunc) Load(ctx context.Context, key string) (interface{}, time.Duration, error) {
+	return f(ctx, key)
+}
+
+// GetOrSet returns the existing value for key if present. Otherwise, or if
+// key holds a negative entry, it stores value and returns it. loaded
+// reports whether the value was already cached. On a closed cache, or if
+// the backing store rejects the write, nothing is stored and actual is nil.
+func (c *Cache) GetOrSet(key string, value interface{}, duration time.Duration) (actual interface{}, loaded bool) {
+	actual, loaded = c.getOrSet(key, value, duration)
+	c.hit(loaded)
+	return actual, loaded
+}
+
+func (c *Cache) getOrSet(key string, value interface{}, duration time.Duration) (interface{}, bool) {
+	if c.closed.Load() {
+		return nil, false
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+
+	if item, ok := s.live(key); ok && !item.Negative {
+		s.trackAccess(key)
+		item.meta.record(s.clock.Now())
+		s.Unlock()
+		return c.valueOf(item), true
+	}
+
+	item := c.newItem(key, value, duration, false)
+	if c.storePut(context.Background(), key, item) != nil {
+		s.Unlock()
+		return nil, false
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return value, false
+}
+
+// GetOrCompute returns the cached value for key, or calls compute and
+// caches its result. Concurrent misses on the same key share a single
+// compute call. A negative entry counts as a miss and is replaced by the
+// result. compute runs without any lock held; if another writer
+// stores key first, that value wins and is returned instead. Errors from
+// compute are returned to every waiter and nothing is cached. If compute
+// panics, the caller that ran it panics and the others get an error. The
+// cache's Loader and stale entries are not consulted.
+func (c *Cache) GetOrCompute(key string, duration time.Duration, compute func() (interface{}, error)) (interface{}, error) {
+	if c.closed.Load() {
+		return nil, ErrCacheClosed
+	}
+
+	item, ok := c.lookup(key)
+	ok = ok && !item.Negative
+	c.hit(ok)
+	if ok {
+		return c.valueOf(item), nil
+	}
+
+	// Computes use their own flights so they never join a Loader call for
+	// the same key.
+	return c.computes.Do(key, func() (interface{}, error) {
+		if item, ok := c.lookup(key); ok && !item.Negative {
+			return c.valueOf(item), nil
+		}
+
+		value, err := compute()
+		if err != nil {
+			return nil, err
+		}
+
+		actual, _ := c.getOrSet(key, value, duration)
+		return actual, nil
+	})
+}
+
+// Fetch returns the value for key, loading it with the cache's Loader on a
+// miss. Without a loader a miss returns ErrKeyNotFound.
+func (c *Cache) Fetch(ctx context.Context, key string) (interface{}, error) {
+	if c.closed.Load() {
+		return nil, ErrCacheClosed
+	}
+
+	item, ok := c.lookup(key)
+	c.hit(ok)
+	if ok {
+		if item.Negative {
+			return nil, keyError(ErrKeyNotFound, key)
+		}
+		c.refreshIfDue(key, item)
+		return c.valueOf(item), nil
+	}
+	if value, ok := c.serveStale(key); ok {
+		return value, nil
+	}
+	if c.loader == nil {
+		return nil, keyError(ErrKeyNotFound, key)
+	}
+	return c.load(ctx, key)
+}
+
+func (c *Cache) load(ctx context.Context, key string) (interface{}, error) {
+	if c.closed.Load() {
+		return nil, ErrCacheClosed
+	}
+
+	return c.flights.Do(key, func() (interface{}, error) {
+		if item, ok := c.lookup(key); ok {
+			if item.Negative {
+				return nil, keyError(ErrKeyNotFound, key)
+			}
+			return c.valueOf(item), nil
+		}
+
+		value, ttl, err := c.loader.Load(ctx, key)
+		if err != nil {
+			if c.negativeTTL > 0 && errors.Is(err, ErrKeyNotFound) {
+				c.SetNegative(key, c.negativeTTL)
+			}
+			return nil, err
+		}
+
+		actual, _ := c.getOrSet(key, value, ttl)
+		return actual, nil
+	})
+}
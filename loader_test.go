@@ -0,0 +1,132 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrCompute(t *testing.T) {
+	errCompute := errors.New("compute failed")
+	tests := []struct {
+		name    string
+		setup   func(c *Cache) error
+		compute func() (interface{}, error)
+		want    interface{}
+		wantErr error
+		calls   int32
+		cached  bool
+	}{
+		{
+			name:    "miss",
+			compute: func() (interface{}, error) { return "computed", nil },
+			want:    "computed",
+			calls:   1,
+			cached:  true,
+		},
+		{
+			name:    "hit",
+			setup:   func(c *Cache) error { return c.Set("key", "cached", 0) },
+			compute: func() (interface{}, error) { return "computed", nil },
+			want:    "cached",
+			calls:   0,
+			cached:  true,
+		},
+		{
+			name:    "negative entry",
+			setup:   func(c *Cache) error { return c.SetNegative("key", time.Minute) },
+			compute: func() (interface{}, error) { return "computed", nil },
+			want:    "computed",
+			calls:   1,
+			cached:  true,
+		},
+		{
+			name:    "error",
+			compute: func() (interface{}, error) { return nil, errCompute },
+			wantErr: errCompute,
+			calls:   1,
+			cached:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(0, 0)
+			if tt.setup != nil {
+				if err := tt.setup(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var calls atomic.Int32
+			got, err := c.GetOrCompute("key", 0, func() (interface{}, error) {
+				calls.Add(1)
+				return tt.compute()
+			})
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("GetOrCompute = %v, %v; want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+			if n := calls.Load(); n != tt.calls {
+				t.Errorf("compute ran %d times, want %d", n, tt.calls)
+			}
+			if v, ok := c.Get("key"); ok != tt.cached || ok && v != tt.want {
+				t.Errorf("Get = %v, %v after GetOrCompute", v, ok)
+			}
+		})
+	}
+}
+
+func TestGetOrComputeSharesMisses(t *testing.T) {
+	c := New(0, 0)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	compute := func() (interface{}, error) {
+		calls.Add(1)
+		<-release
+		return "computed", nil
+	}
+
+	const n = 8
+	var wg sync.WaitGroup
+	results := make([]interface{}, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.GetOrCompute("key", 0, compute)
+		}()
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// Let the other callers reach the shared flight.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("compute ran %d times, want 1", got)
+	}
+	for i, v := range results {
+		if v != "computed" {
+			t.Errorf("caller %d got %v", i, v)
+		}
+	}
+}
+
+func TestGetOrComputeIgnoresLoader(t *testing.T) {
+	var loads atomic.Int32
+	c := New(0, 0, WithLoader(LoaderFunc(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		loads.Add(1)
+		return "loaded", 0, nil
+	})))
+
+	got, err := c.GetOrCompute("key", 0, func() (interface{}, error) { return "computed", nil })
+	if err != nil || got != "computed" {
+		t.Errorf("GetOrCompute = %v, %v; want computed, nil", got, err)
+	}
+	if n := loads.Load(); n != 0 {
+		t.Errorf("Loader ran %d times", n)
+	}
+}
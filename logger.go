@@ -0,0 +1,34 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"log/slog"
+)
+
+// overflowed accounts for entries evicted to make room for a write and
+// hands them to the OnEvicted callback.
+func (c *Cache) overflowed(evicted []keyedItem) {
+	if len(evicted) == 0 {
+		return
+	}
+	c.stats.evictions.Add(uint64(len(evicted)))
+	if c.logger != nil && c.logger.Enabled(context.Background(), slog.LevelDebug) {
+		for _, e := range evicted {
+			c.logger.Debug("cache entry evicted", slog.String("key", e.key))
+		}
+	}
+	c.evicted(evicted)
+}
+
+// callEvicted runs the OnEvicted callback. With a logger, a panicking
+// callback is logged and recovered instead of crashing the caller.
+func (c *Cache) callEvicted(key string, value interface{}) {
+	if c.logger != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				c.logger.Error("OnEvicted callback panicked", slog.String("key", key), slog.Any("panic", r))
+			}
+		}()
+	}
+	c.onEvicted(key, value)
+}
@@ -0,0 +1,301 @@
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	cache "go-in-memory-cache"
+)
+
+const (
+	maxKeyLen   = 250
+	maxValueLen = 1 << 20
+
+	// Exptimes above this many seconds are absolute Unix times.
+	relativeLimit = 60 * 60 * 24 * 30
+)
+
+var (
+	errNotFound   = errors.New("not found")
+	errNotNumeric = errors.New("cannot increment or decrement non-numeric value")
+)
+
+// exec runs the command on line, reading its data block from r if it has
+// one. It reports whether the connection should be closed, and returns an
+// error only when r can no longer be read.
+func (s *Server) exec(r *bufio.Reader, w *bufio.Writer, line []byte) (bool, error) {
+	fields := bytes.Fields(line)
+	if len(fields) == 0 {
+		w.WriteString("ERROR\r\n")
+		return false, nil
+	}
+
+	// Every command but get accepts a trailing noreply.
+	name := string(fields[0])
+	if name != "get" && len(fields) > 1 && string(fields[len(fields)-1]) == "noreply" {
+		fields = fields[:len(fields)-1]
+		w = bufio.NewWriter(io.Discard)
+	}
+
+	switch name {
+	case "get":
+		if len(fields) < 2 {
+			w.WriteString("ERROR\r\n")
+			break
+		}
+		for _, key := range fields[1:] {
+			s.get(w, string(key))
+		}
+		w.WriteString("END\r\n")
+	case "set", "add", "replace":
+		return false, s.store(r, w, name, fields)
+	case "delete":
+		if len(fields) != 2 {
+			w.WriteString("ERROR\r\n")
+			break
+		}
+		switch err := s.cache.Delete(string(fields[1])); {
+		case errors.Is(err, cache.ErrKeyNotFound):
+			w.WriteString("NOT_FOUND\r\n")
+		case err != nil:
+			fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+		default:
+			w.WriteString("DELETED\r\n")
+		}
+	case "incr", "decr":
+		if len(fields) != 3 {
+			w.WriteString("ERROR\r\n")
+			break
+		}
+		s.incr(w, string(fields[1]), fields[2], name == "decr")
+	case "touch":
+		if len(fields) != 3 {
+			w.WriteString("ERROR\r\n")
+			break
+		}
+		s.touch(w, string(fields[1]), fields[2])
+	case "flush_all":
+		if err := s.cache.Flush(); err != nil {
+			fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+			break
+		}
+		w.WriteString("OK\r\n")
+	case "version":
+		w.WriteString("VERSION go-in-memory-cache\r\n")
+	case "quit":
+		return true, nil
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+
+	return false, nil
+}
+
+func (s *Server) get(w *bufio.Writer, key string) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return
+	}
+
+	var flags uint32
+	var data []byte
+	switch v := value.(type) {
+	case Entry:
+		flags, data = v.Flags, v.Data
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		data = fmt.Appendf(nil, "%d", v)
+	default:
+		// Values stored from Go that have no wire form are reported as
+		// misses.
+		return
+	}
+
+	fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, flags, len(data))
+	w.Write(data)
+	w.WriteString("\r\n")
+}
+
+func (s *Server) store(r *bufio.Reader, w *bufio.Writer, name string, fields [][]byte) error {
+	if len(fields) != 5 {
+		w.WriteString("ERROR\r\n")
+		return nil
+	}
+	key := string(fields[1])
+	flags, err1 := strconv.ParseUint(string(fields[2]), 10, 32)
+	exptime, err2 := strconv.ParseInt(string(fields[3]), 10, 64)
+	size, err3 := strconv.Atoi(string(fields[4]))
+	if err3 != nil || size < 0 {
+		w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return nil
+	}
+
+	var data []byte
+	if size <= maxValueLen {
+		data = make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return err
+		}
+	} else if _, err := r.Discard(size + 2); err != nil {
+		return err
+	}
+
+	switch {
+	case err1 != nil || err2 != nil || len(key) > maxKeyLen:
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return nil
+	case size > maxValueLen:
+		w.WriteString("SERVER_ERROR object too large for cache\r\n")
+		return nil
+	case !bytes.HasSuffix(data, []byte("\r\n")):
+		w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return nil
+	}
+
+	var value interface{} = data[:size]
+	if flags != 0 {
+		value = Entry{Flags: uint32(flags), Data: data[:size]}
+	}
+
+	duration, expired := s.lifetime(exptime)
+	if expired {
+		// The entry would be invisible as soon as it is stored; apply the
+		// mode's precondition and drop whatever the key held.
+		_, exists := s.cache.TTL(key)
+		if (name == "add" && exists) || (name == "replace" && !exists) {
+			w.WriteString("NOT_STORED\r\n")
+			return nil
+		}
+		if err := s.cache.Delete(key); err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+			fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+			return nil
+		}
+		w.WriteString("STORED\r\n")
+		return nil
+	}
+
+	var err error
+	switch name {
+	case "add":
+		err = s.cache.Add(key, value, duration)
+	case "replace":
+		err = s.cache.Replace(key, value, duration)
+	default:
+		err = s.cache.Set(key, value, duration)
+	}
+	switch {
+	case errors.Is(err, cache.ErrKeyExists), errors.Is(err, cache.ErrKeyNotFound):
+		w.WriteString("NOT_STORED\r\n")
+	case err != nil:
+		fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+	default:
+		w.WriteString("STORED\r\n")
+	}
+	return nil
+}
+
+func (s *Server) incr(w *bufio.Writer, key string, arg []byte, decr bool) {
+	delta, err := strconv.ParseUint(string(arg), 10, 64)
+	if err != nil {
+		w.WriteString("CLIENT_ERROR invalid numeric delta argument\r\n")
+		return
+	}
+
+	var n uint64
+	_, err = s.cache.Update(key, func(old interface{}, exists bool) (interface{}, error) {
+		if !exists {
+			return nil, errNotFound
+		}
+
+		var flags uint32
+		var data []byte
+		switch v := old.(type) {
+		case Entry:
+			flags, data = v.Flags, v.Data
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			return nil, errNotNumeric
+		}
+
+		cur, err := strconv.ParseUint(string(data), 10, 64)
+		if err != nil {
+			return nil, errNotNumeric
+		}
+		switch {
+		case !decr:
+			// incr wraps around at 64 bits like memcached.
+			n = cur + delta
+		case delta > cur:
+			n = 0
+		default:
+			n = cur - delta
+		}
+
+		data = strconv.AppendUint(nil, n, 10)
+		if flags != 0 {
+			return Entry{Flags: flags, Data: data}, nil
+		}
+		return data, nil
+	}, cache.KeepTTL)
+
+	switch {
+	case errors.Is(err, errNotFound):
+		w.WriteString("NOT_FOUND\r\n")
+	case errors.Is(err, errNotNumeric):
+		w.WriteString("CLIENT_ERROR cannot increment or decrement non-numeric value\r\n")
+	case err != nil:
+		fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+	default:
+		fmt.Fprintf(w, "%d\r\n", n)
+	}
+}
+
+func (s *Server) touch(w *bufio.Writer, key string, arg []byte) {
+	exptime, err := strconv.ParseInt(string(arg), 10, 64)
+	if err != nil {
+		w.WriteString("CLIENT_ERROR invalid exptime argument\r\n")
+		return
+	}
+
+	duration, expired := s.lifetime(exptime)
+	if expired {
+		err = s.cache.Delete(key)
+	} else {
+		err = s.cache.Touch(key, duration)
+	}
+	switch {
+	case errors.Is(err, cache.ErrKeyNotFound):
+		w.WriteString("NOT_FOUND\r\n")
+	case err != nil:
+		fmt.Fprintf(w, "SERVER_ERROR %v\r\n", err)
+	default:
+		w.WriteString("TOUCHED\r\n")
+	}
+}
+
+// lifetime converts a memcached exptime into a cache duration, reporting
+// whether the entry is already expired. Absolute times are measured
+// against the cache's clock.
+func (s *Server) lifetime(exptime int64) (time.Duration, bool) {
+	switch {
+	case exptime < 0:
+		return 0, true
+	case exptime == 0:
+		return 0, false
+	case exptime > relativeLimit:
+		d := time.Unix(exptime, 0).Sub(s.cache.Clock().Now())
+		return d, d <= 0
+	}
+	return time.Duration(exptime) * time.Second, false
+}
@@ -0,0 +1,84 @@
+// Package memcached serves a cache over the memcached ASCII protocol so that
+// existing memcached clients and tooling work against it unmodified.
+//
+// Supported commands are get, set, add, replace, delete, incr, decr, touch,
+// flush_all, version and quit. An exptime of 0 uses the cache's default
+// lifetime; values above 30 days are absolute Unix times as in memcached.
+package memcached
+
+import (
+	"bufio"
+	"errors"
+	"net"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/internal/netserver"
+)
+
+var ErrServerClosed = errors.New("memcached: server closed")
+
+// Entry is stored for values written with non-zero client flags, so the
+// flags can be returned on get. Values with zero flags are stored as plain
+// []byte.
+type Entry struct {
+	Flags uint32
+	Data  []byte
+}
+
+type Server struct {
+	cache *cache.Cache
+	srv   *netserver.Server
+}
+
+func NewServer(c *cache.Cache) *Server {
+	s := &Server{cache: c}
+	s.srv = netserver.New(s.serveConn, ErrServerClosed)
+	return s
+}
+
+// ListenAndServe listens on the TCP address addr and serves requests until
+// the server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	return s.srv.ListenAndServe(addr)
+}
+
+// Serve accepts connections on l until it fails or the server is closed,
+// in which case it returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	return s.srv.Serve(l)
+}
+
+// Close stops all listeners, closes open connections and waits for their
+// handlers to return. It does not close the cache.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
+
+// serveConn serves conn until the client quits or the connection fails.
+func (s *Server) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadSlice('\n')
+		if err != nil {
+			if err == bufio.ErrBufferFull {
+				w.WriteString("CLIENT_ERROR line too long\r\n")
+				w.Flush()
+			}
+			return
+		}
+
+		quit, err := s.exec(r, w, line)
+		if err != nil {
+			return
+		}
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package memcached
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// step sends a request and expects exactly want in reply, nothing if want
+// is empty. A step with act runs it against the cache and clock instead.
+type step struct {
+	send string
+	want string
+	act  func(c *cache.Cache, clock *cache.FakeClock)
+}
+
+// serve starts a server for c and returns a connection to it.
+func serve(t *testing.T, c *cache.Cache) net.Conn {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(c)
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(l) }()
+	t.Cleanup(func() {
+		s.Close()
+		if err := <-done; !errors.Is(err, ErrServerClosed) {
+			t.Errorf("Serve = %v, want %v", err, ErrServerClosed)
+		}
+	})
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServer(t *testing.T) {
+	absolute := strconv.FormatInt(epoch.Add(time.Minute).Unix(), 10)
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "set and get", steps: []step{
+			{send: "set k 0 0 5\r\nhello\r\n", want: "STORED\r\n"},
+			{send: "get k missing\r\n", want: "VALUE k 0 5\r\nhello\r\nEND\r\n"},
+		}},
+		{name: "flags", steps: []step{
+			{send: "set k 42 0 2\r\nhi\r\n", want: "STORED\r\n"},
+			{send: "get k\r\n", want: "VALUE k 42 2\r\nhi\r\nEND\r\n"},
+		}},
+		{name: "add and replace", steps: []step{
+			{send: "replace k 0 0 1\r\na\r\n", want: "NOT_STORED\r\n"},
+			{send: "add k 0 0 1\r\na\r\n", want: "STORED\r\n"},
+			{send: "add k 0 0 1\r\nb\r\n", want: "NOT_STORED\r\n"},
+			{send: "replace k 0 0 1\r\nc\r\n", want: "STORED\r\n"},
+			{send: "get k\r\n", want: "VALUE k 0 1\r\nc\r\nEND\r\n"},
+		}},
+		{name: "delete", steps: []step{
+			{send: "set k 0 0 1\r\na\r\n", want: "STORED\r\n"},
+			{send: "delete k\r\n", want: "DELETED\r\n"},
+			{send: "delete k\r\n", want: "NOT_FOUND\r\n"},
+		}},
+		{name: "delete frozen", steps: []step{
+			{send: "set k 0 0 1\r\na\r\n", want: "STORED\r\n"},
+			{act: func(c *cache.Cache, _ *cache.FakeClock) { c.Freeze() }},
+			{send: "delete k\r\n", want: "SERVER_ERROR cache is frozen\r\n"},
+			{send: "get k\r\n", want: "VALUE k 0 1\r\na\r\nEND\r\n"},
+		}},
+		{name: "incr and decr", steps: []step{
+			{send: "incr n 1\r\n", want: "NOT_FOUND\r\n"},
+			{send: "set n 0 0 2\r\n10\r\n", want: "STORED\r\n"},
+			{send: "incr n 5\r\n", want: "15\r\n"},
+			{send: "decr n 20\r\n", want: "0\r\n"},
+			{send: "set s 0 0 1\r\nx\r\n", want: "STORED\r\n"},
+			{send: "incr s 1\r\n", want: "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n"},
+		}},
+		{name: "relative exptime", steps: []step{
+			{send: "set k 0 60 1\r\na\r\n", want: "STORED\r\n"},
+			{act: func(_ *cache.Cache, clock *cache.FakeClock) { clock.Advance(61 * time.Second) }},
+			{send: "get k\r\n", want: "END\r\n"},
+		}},
+		{name: "absolute exptime", steps: []step{
+			{send: "set k 0 " + absolute + " 1\r\na\r\n", want: "STORED\r\n"},
+			{send: "get k\r\n", want: "VALUE k 0 1\r\na\r\nEND\r\n"},
+			{act: func(_ *cache.Cache, clock *cache.FakeClock) { clock.Advance(61 * time.Second) }},
+			{send: "get k\r\n", want: "END\r\n"},
+		}},
+		{name: "negative exptime", steps: []step{
+			{send: "set k 0 0 1\r\na\r\n", want: "STORED\r\n"},
+			{send: "set k 0 -1 1\r\nb\r\n", want: "STORED\r\n"},
+			{send: "get k\r\n", want: "END\r\n"},
+		}},
+		{name: "touch", steps: []step{
+			{send: "touch k 10\r\n", want: "NOT_FOUND\r\n"},
+			{send: "set k 0 5 1\r\na\r\n", want: "STORED\r\n"},
+			{send: "touch k 60\r\n", want: "TOUCHED\r\n"},
+			{act: func(_ *cache.Cache, clock *cache.FakeClock) { clock.Advance(30 * time.Second) }},
+			{send: "get k\r\n", want: "VALUE k 0 1\r\na\r\nEND\r\n"},
+		}},
+		{name: "noreply", steps: []step{
+			{send: "set k 0 0 1 noreply\r\na\r\n"},
+			{send: "get k\r\n", want: "VALUE k 0 1\r\na\r\nEND\r\n"},
+		}},
+		{name: "flush_all", steps: []step{
+			{send: "set k 0 0 1\r\na\r\n", want: "STORED\r\n"},
+			{send: "flush_all\r\n", want: "OK\r\n"},
+			{send: "get k\r\n", want: "END\r\n"},
+		}},
+		{name: "bad data chunk", steps: []step{
+			{send: "set k 0 0 1\r\nabc\r\n", want: "CLIENT_ERROR bad data chunk\r\n"},
+		}},
+		{name: "unknown command", steps: []step{
+			{send: "bogus\r\n", want: "ERROR\r\n"},
+			{send: "version\r\n", want: "VERSION go-in-memory-cache\r\n"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := cache.NewFakeClock(epoch)
+			c := cache.New(0, 0, cache.WithClock(clock))
+			defer c.Close()
+			conn := serve(t, c)
+
+			for _, st := range tt.steps {
+				if st.act != nil {
+					st.act(c, clock)
+					continue
+				}
+				if _, err := io.WriteString(conn, st.send); err != nil {
+					t.Fatal(err)
+				}
+				if st.want == "" {
+					continue
+				}
+				conn.SetReadDeadline(time.Now().Add(time.Second))
+				got := make([]byte, len(st.want))
+				if _, err := io.ReadFull(conn, got); err != nil {
+					t.Fatalf("%q: %v (read %q)", st.send, err, got)
+				}
+				if string(got) != st.want {
+					t.Fatalf("%q: got %q, want %q", st.send, got, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	s := NewServer(cache.New(0, 0))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); !errors.Is(err, ErrServerClosed) {
+		t.Errorf("second Close = %v, want %v", err, ErrServerClosed)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Serve(l); !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Serve after Close = %v, want %v", err, ErrServerClosed)
+	}
+}
@@ -0,0 +1,125 @@
+package go_in_memory_cache
+
+import (
+	"container/heap"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// accessMeta is shared by every copy of a stored Item so that readers
+// holding only the read lock can record accesses.
+type accessMeta struct {
+	last atomic.Int64
+	hits atomic.Uint64
+}
+
+func (m *accessMeta) record(now time.Time) {
+	if m == nil {
+		return
+	}
+	m.last.Store(now.UnixNano())
+	m.hits.Add(1)
+}
+
+// Metadata describes an entry without its value.
+type Metadata struct {
+	Created time.Time
+	// Expires is zero for entries that never expire.
+	Expires time.Time
+	// LastAccessed is zero if the entry was never read.
+	LastAccessed time.Time
+	// AccessCount counts reads since the entry was last stored with a new
+	// value.
+	AccessCount uint64
+	Cost        int64
+	Version     uint64
+}
+
+// Metadata returns access statistics for key without counting as an access.
+func (c *Cache) Metadata(key string) (Metadata, bool) {
+	if c.closed.Load() {
+		return Metadata{}, false
+	}
+
+	s := c.shardFor(key)
+	s.RLock()
+	item, ok := s.live(key)
+	s.RUnlock()
+	if !ok {
+		return Metadata{}, false
+	}
+
+	md := Metadata{Created: item.Created, Cost: item.cost, Version: item.Version}
+	if item.Expired > 0 {
+		md.Expires = time.Unix(0, item.Expired)
+	}
+	if item.meta != nil {
+		if last := item.meta.last.Load(); last > 0 {
+			md.LastAccessed = time.Unix(0, last)
+		}
+		md.AccessCount = item.meta.hits.Load()
+	}
+	return md, true
+}
+
+type KeyCount struct {
+	Key  string
+	Hits uint64
+}
+
+// keyCountHeap is a min-heap, so the least accessed of the current top
+// keys is the one replaced.
+type keyCountHeap []KeyCount
+
+func (h keyCountHeap) Len() int { return len(h) }
+
+func (h keyCountHeap) Less(i, j int) bool { return h[i].Hits < h[j].Hits }
+
+func (h keyCountHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *keyCountHeap) Push(x interface{}) { *h = append(*h, x.(KeyCount)) }
+
+func (h *keyCountHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// TopKeys returns up to n live keys with the highest AccessCount, most
+// accessed first. It reads the per-entry counters in one pass, keeping
+// only n candidates in memory.
+func (c *Cache) TopKeys(n int) []KeyCount {
+	if n <= 0 || c.closed.Load() {
+		return nil
+	}
+
+	top := make(keyCountHeap, 0, n)
+	for _, s := range c.shards {
+		s.RLock()
+		for key := range s.items {
+			item, ok := s.live(key)
+			if !ok || item.meta == nil {
+				continue
+			}
+			kc := KeyCount{Key: key, Hits: item.meta.hits.Load()}
+			switch {
+			case len(top) < n:
+				heap.Push(&top, kc)
+			case kc.Hits > top[0].Hits:
+				top[0] = kc
+				heap.Fix(&top, 0)
+			}
+		}
+		s.RUnlock()
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Hits != top[j].Hits {
+			return top[i].Hits > top[j].Hits
+		}
+		return top[i].Key < top[j].Key
+	})
+	return top
+}
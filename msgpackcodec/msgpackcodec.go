@@ -0,0 +1,23 @@
+// Package msgpackcodec provides a MessagePack implementation of the cache
+// Codec interface.
+package msgpackcodec
+
+import (
+	cache "go-in-memory-cache"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type codec struct{}
+
+// Codec encodes values with MessagePack. Use it with cache.WithCodec or
+// cache.WithValueCopy.
+var Codec cache.Codec = codec{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
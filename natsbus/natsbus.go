@@ -0,0 +1,63 @@
+// Package natsbus implements cache.Broadcaster over NATS core pub/sub.
+// Invalidations are published as JSON on a single subject.
+package natsbus
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	cache "go-in-memory-cache"
+
+	"github.com/nats-io/nats.go"
+)
+
+type Broadcaster struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// New returns a Broadcaster publishing to and subscribing on subject.
+func New(conn *nats.Conn, subject string) *Broadcaster {
+	return &Broadcaster{conn: conn, subject: subject}
+}
+
+func (b *Broadcaster) Publish(ctx context.Context, msg cache.Invalidation) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(b.subject, data)
+}
+
+// Subscribe blocks until ctx is done or the connection is closed. fn runs
+// on the subscription's goroutine. Messages that are not valid
+// invalidations are ignored.
+func (b *Broadcaster) Subscribe(ctx context.Context, fn func(cache.Invalidation)) error {
+	sub, err := b.conn.Subscribe(b.subject, func(m *nats.Msg) {
+		var msg cache.Invalidation
+		if err := json.Unmarshal(m.Data, &msg); err != nil {
+			return
+		}
+		fn(msg)
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	// The connection's closed handler belongs to the caller, so closure is
+	// noticed by polling.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if b.conn.IsClosed() {
+				return nats.ErrConnectionClosed
+			}
+		}
+	}
+}
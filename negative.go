@@ -0,0 +1,43 @@
+package go_in_memory_cache
+
+import "time"
+
+// State tells Lookup's callers why a value is or is not available.
+type State int
+
+const (
+	// StateMissing means the cache knows nothing about the key.
+	StateMissing State = iota
+	// StateFound means the key holds a value.
+	StateFound
+	// StateNegative means the key was cached as not existing upstream.
+	StateNegative
+)
+
+// SetNegative records that key does not exist upstream, for ttl or the
+// WithNegativeCaching lifetime when ttl is 0. Get reports such keys as
+// missing without calling the Loader, Fetch returns ErrKeyNotFound and
+// Lookup returns StateNegative.
+func (c *Cache) SetNegative(key string, ttl time.Duration) error {
+	if ttl == 0 && c.negativeTTL > 0 {
+		ttl = c.negativeTTL
+	}
+	item := c.newItem(key, nil, ttl, false)
+	item.Negative = true
+	return c.setItem(key, item, setAlways)
+}
+
+// Lookup returns the value for key together with its state, distinguishing
+// keys the cache knows nothing about from keys cached as not found. It does
+// not call the Loader.
+func (c *Cache) Lookup(key string) (interface{}, State) {
+	item, ok := c.lookup(key)
+	c.hit(ok)
+	switch {
+	case !ok:
+		return nil, StateMissing
+	case item.Negative:
+		return nil, StateNegative
+	}
+	return c.valueOf(item), StateFound
+}
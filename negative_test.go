@@ -0,0 +1,49 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNegativeEntriesAreMisses(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(c *Cache) bool
+	}{
+		{name: "Get", read: func(c *Cache) bool {
+			_, ok := c.Get("key")
+			return ok
+		}},
+		{name: "GetItem", read: func(c *Cache) bool {
+			_, ok := c.GetItem("key")
+			return ok
+		}},
+		{name: "Fetch", read: func(c *Cache) bool {
+			_, err := c.Fetch(context.Background(), "key")
+			if !errors.Is(err, ErrKeyNotFound) {
+				t.Errorf("Fetch = %v, want %v", err, ErrKeyNotFound)
+			}
+			return err == nil
+		}},
+		{name: "Tiered Get", read: func(c *Cache) bool {
+			_, ok := Tiered(New(0, 0), c).Get("key")
+			return ok
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(0, 0)
+			if err := c.SetNegative("key", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if tt.read(c) {
+				t.Error("negative entry reported as a hit")
+			}
+			if _, state := c.Lookup("key"); state != StateNegative {
+				t.Errorf("Lookup state = %v, want StateNegative", state)
+			}
+		})
+	}
+}
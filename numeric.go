@@ -0,0 +1,160 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Increment adds delta to the integer stored under key and returns the new
+// value. The stored value keeps its original type and expiry. It fails if
+// key is missing or does not hold an integer, or if the new value would not
+// fit in the stored type or in an int64.
+func (c *Cache) Increment(key string, delta int64) (int64, error) {
+	var result int64
+	err := c.modify(key, func(value interface{}) (v interface{}, err error) {
+		v, result, err = addInt(key, value, delta)
+		return v, err
+	})
+	return result, err
+}
+
+// addInt adds delta to the integer value, keeping its type, and returns the
+// sum both as that type and as an int64. A sum that does not fit in the
+// value's type, or in an int64, fails with nothing changed.
+func addInt(key string, value interface{}, delta int64) (interface{}, int64, error) {
+	switch v := value.(type) {
+	case int:
+		return addSigned(key, v, delta, math.MinInt, math.MaxInt)
+	case int8:
+		return addSigned(key, v, delta, math.MinInt8, math.MaxInt8)
+	case int16:
+		return addSigned(key, v, delta, math.MinInt16, math.MaxInt16)
+	case int32:
+		return addSigned(key, v, delta, math.MinInt32, math.MaxInt32)
+	case int64:
+		return addSigned(key, v, delta, math.MinInt64, math.MaxInt64)
+	case uint:
+		return addUnsigned(key, v, delta, math.MaxUint)
+	case uint8:
+		return addUnsigned(key, v, delta, math.MaxUint8)
+	case uint16:
+		return addUnsigned(key, v, delta, math.MaxUint16)
+	case uint32:
+		return addUnsigned(key, v, delta, math.MaxUint32)
+	case uint64:
+		return addUnsigned(key, v, delta, math.MaxUint64)
+	default:
+		return nil, 0, fmt.Errorf("%w: value for %q is %T, not an integer", ErrTypeMismatch, key, value)
+	}
+}
+
+// addSigned adds delta to v, failing if the sum leaves [lo, hi].
+func addSigned[T int | int8 | int16 | int32 | int64](key string, v T, delta, lo, hi int64) (interface{}, int64, error) {
+	sum := int64(v)
+	if delta > 0 && sum > math.MaxInt64-delta || delta < 0 && sum < math.MinInt64-delta {
+		return nil, 0, errOverflow(key)
+	}
+	sum += delta
+	if sum < lo || sum > hi {
+		return nil, 0, errOverflow(key)
+	}
+	return T(sum), sum, nil
+}
+
+// addUnsigned adds delta to v, failing if the sum drops below zero or
+// exceeds hi or math.MaxInt64.
+func addUnsigned[T uint | uint8 | uint16 | uint32 | uint64](key string, v T, delta int64, hi uint64) (interface{}, int64, error) {
+	sum := uint64(v)
+	if underflows(sum, delta) {
+		return nil, 0, errUnderflow(key)
+	}
+	if delta < 0 {
+		sum -= uint64(-delta)
+	} else {
+		if uint64(delta) > hi-sum {
+			return nil, 0, errOverflow(key)
+		}
+		sum += uint64(delta)
+	}
+	if sum > hi || sum > math.MaxInt64 {
+		return nil, 0, errOverflow(key)
+	}
+	return T(sum), int64(sum), nil
+}
+
+func underflows(v uint64, delta int64) bool {
+	return delta < 0 && uint64(-delta) > v
+}
+
+func errUnderflow(key string) error {
+	return fmt.Errorf("decrementing %q would drop an unsigned value below zero", key)
+}
+
+func errOverflow(key string) error {
+	return fmt.Errorf("the new value of %q would not fit in its type or in an int64", key)
+}
+
+func (c *Cache) Decrement(key string, delta int64) (int64, error) {
+	return c.Increment(key, -delta)
+}
+
+// IncrementFloat adds delta to the float32 or float64 stored under key and
+// returns the new value.
+func (c *Cache) IncrementFloat(key string, delta float64) (float64, error) {
+	var result float64
+	err := c.modify(key, func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case float32:
+			v += float32(delta)
+			result = float64(v)
+			return v, nil
+		case float64:
+			v += delta
+			result = v
+			return v, nil
+		default:
+			return nil, fmt.Errorf("%w: value for %q is %T, not a float", ErrTypeMismatch, key, value)
+		}
+	})
+	return result, err
+}
+
+func (c *Cache) DecrementFloat(key string, delta float64) (float64, error) {
+	return c.IncrementFloat(key, -delta)
+}
+
+// modify replaces the live value under key with the result of fn while
+// holding the shard's write lock. Expiry and creation time are preserved.
+func (c *Cache) modify(key string, fn func(value interface{}) (interface{}, error)) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+
+	item, ok := s.live(key)
+	if !ok {
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+
+	value, err := fn(item.Value)
+	if err != nil {
+		s.Unlock()
+		return err
+	}
+
+	item.Value = value
+	if err := c.storePut(context.Background(), key, item); err != nil {
+		s.Unlock()
+		return err
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return nil
+}
@@ -0,0 +1,56 @@
+package go_in_memory_cache
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIncrement(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		delta   int64
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "int", value: 1, delta: 2, want: 3},
+		{name: "int8 max", value: int8(126), delta: 1, want: int8(127)},
+		{name: "int8 overflow", value: int8(127), delta: 1, wantErr: true},
+		{name: "int8 underflow", value: int8(-128), delta: -1, wantErr: true},
+		{name: "int8 large delta", value: int8(0), delta: 256, wantErr: true},
+		{name: "int16 overflow", value: int16(math.MaxInt16), delta: 1, wantErr: true},
+		{name: "int32 overflow", value: int32(math.MaxInt32), delta: 1, wantErr: true},
+		{name: "int64 overflow", value: int64(math.MaxInt64), delta: 1, wantErr: true},
+		{name: "int64 underflow", value: int64(math.MinInt64), delta: -1, wantErr: true},
+		{name: "int64 decrement", value: int64(5), delta: -7, want: int64(-2)},
+		{name: "uint8 max", value: uint8(254), delta: 1, want: uint8(255)},
+		{name: "uint8 overflow", value: uint8(255), delta: 1, wantErr: true},
+		{name: "uint8 large delta", value: uint8(0), delta: 256, wantErr: true},
+		{name: "uint8 below zero", value: uint8(0), delta: -1, wantErr: true},
+		{name: "uint16 overflow", value: uint16(math.MaxUint16), delta: 1, wantErr: true},
+		{name: "uint32 overflow", value: uint32(math.MaxUint32), delta: 1, wantErr: true},
+		{name: "uint64 beyond int64", value: uint64(math.MaxInt64), delta: 1, wantErr: true},
+		{name: "uint64 stored beyond int64", value: uint64(math.MaxUint64), delta: -1, wantErr: true},
+		{name: "uint decrement", value: uint(5), delta: -5, want: uint(0)},
+		{name: "not an integer", value: "1", delta: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(0, 0)
+			if err := c.Set("n", tt.value, 0); err != nil {
+				t.Fatal(err)
+			}
+			_, err := c.Increment("n", tt.delta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Increment = %v, want error %v", err, tt.wantErr)
+			}
+			want := tt.want
+			if tt.wantErr {
+				want = tt.value
+			}
+			if got, _ := c.Get("n"); got != want {
+				t.Errorf("stored %v (%T), want %v (%T)", got, got, want, want)
+			}
+		})
+	}
+}
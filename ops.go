@@ -0,0 +1,237 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Pop removes key and returns its value in a single locked step. If the
+// backing store rejects the delete, key is left alone and Pop reports a
+// miss.
+func (c *Cache) Pop(key string) (interface{}, bool) {
+	if c.writable() != nil {
+		return nil, false
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+	item, ok := s.live(key)
+	if ok && c.storeDelete(context.Background(), key) != nil {
+		s.Unlock()
+		return nil, false
+	}
+	if ok {
+		s.remove(key)
+	}
+	s.Unlock()
+
+	c.hit(ok)
+	if !ok {
+		return nil, false
+	}
+	c.deleted([]keyedItem{{key: key, item: item}})
+	c.invalidate(key)
+	return c.valueOf(item), true
+}
+
+// GetSet stores value under key and returns the value it replaced, if any,
+// in a single locked step. If the backing store rejects the write nothing
+// is stored and existed is false.
+func (c *Cache) GetSet(key string, value interface{}, duration time.Duration) (old interface{}, existed bool) {
+	if c.writable() != nil {
+		return nil, false
+	}
+
+	item := c.newItem(key, value, duration, false)
+
+	s := c.shardFor(key)
+	s.Lock()
+	prev, existed := s.live(key)
+	if c.storePut(context.Background(), key, item) != nil {
+		s.Unlock()
+		return nil, false
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.hit(existed)
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return c.valueOf(prev), existed
+}
+
+// CompareAndSwap stores value under key only if key currently holds a live
+// entry equal to old. Values are compared with == when their type is
+// comparable and with reflect.DeepEqual otherwise. It also reports false if
+// the backing store rejects the write.
+func (c *Cache) CompareAndSwap(key string, old, value interface{}, duration time.Duration) bool {
+	if c.writable() != nil {
+		return false
+	}
+
+	item := c.newItem(key, value, duration, false)
+
+	s := c.shardFor(key)
+	s.Lock()
+	cur, ok := s.live(key)
+	if !ok || !valuesEqual(c.rawValue(cur), old) || c.storePut(context.Background(), key, item) != nil {
+		s.Unlock()
+		return false
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return true
+}
+
+// CompareAndDelete removes key only if it currently holds a live entry
+// equal to old, compared as in CompareAndSwap, and the backing store
+// accepts the delete.
+func (c *Cache) CompareAndDelete(key string, old interface{}) bool {
+	if c.writable() != nil {
+		return false
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+	cur, ok := s.live(key)
+	if !ok || !valuesEqual(c.rawValue(cur), old) || c.storeDelete(context.Background(), key) != nil {
+		s.Unlock()
+		return false
+	}
+	s.remove(key)
+	s.Unlock()
+
+	c.deleted([]keyedItem{{key: key, item: cur}})
+	c.invalidate(key)
+	return true
+}
+
+// Swap exchanges the entries under keyA and keyB, values and expiry
+// together, in a single locked step. Both keys must hold live entries.
+func (c *Cache) Swap(keyA, keyB string) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	unlock := c.lockKeys(keyA, keyB)
+	sa, sb := c.shardFor(keyA), c.shardFor(keyB)
+	a, ok := sa.live(keyA)
+	if !ok {
+		unlock()
+		return keyError(ErrKeyNotFound, keyA)
+	}
+	b, ok := sb.live(keyB)
+	if !ok {
+		unlock()
+		return keyError(ErrKeyNotFound, keyB)
+	}
+	if keyA == keyB {
+		unlock()
+		return nil
+	}
+	if err := c.storePut(context.Background(), keyA, b); err != nil {
+		unlock()
+		return err
+	}
+	if err := c.storePut(context.Background(), keyB, a); err != nil {
+		unlock()
+		return err
+	}
+	evicted := sa.store(keyA, b)
+	evicted = append(evicted, sb.store(keyB, a)...)
+	unlock()
+
+	c.overflowed(evicted)
+	c.invalidate(keyA, keyB)
+	return nil
+}
+
+// SetIfVersion stores value under key only if the key's current Version
+// equals version, and returns the new Version. A version of 0 requires the
+// key to hold no live entry. On a mismatch it fails with
+// ErrVersionMismatch.
+func (c *Cache) SetIfVersion(key string, value interface{}, version uint64, duration time.Duration) (uint64, error) {
+	if err := c.writable(); err != nil {
+		return 0, err
+	}
+
+	item := c.newItem(key, value, duration, false)
+
+	s := c.shardFor(key)
+	s.Lock()
+	if s.maxCost > 0 && s.weigh(key, item.Value) > s.maxCost {
+		s.Unlock()
+		return 0, keyError(ErrCapacityExceeded, key)
+	}
+	cur, _ := s.live(key)
+	if cur.Version != version {
+		s.Unlock()
+		return 0, keyError(ErrVersionMismatch, key)
+	}
+	if err := c.storePut(context.Background(), key, item); err != nil {
+		s.Unlock()
+		return 0, err
+	}
+	evicted := s.store(key, item)
+	version = s.version
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return version, nil
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if reflect.TypeOf(a).Comparable() && reflect.TypeOf(b).Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Update runs fn with the current value of key while holding the key's
+// shard lock and stores what it returns, enabling read-modify-write without
+// races. exists is false when key holds no live entry. If fn returns an
+// error nothing is stored. fn must not call back into the cache. Pass
+// KeepTTL as duration to leave the entry's expiry unchanged.
+func (c *Cache) Update(key string, fn func(old interface{}, exists bool) (interface{}, error), duration time.Duration) (interface{}, error) {
+	if err := c.writable(); err != nil {
+		return nil, err
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+	cur, ok := s.live(key)
+	value, err := fn(c.valueOf(cur), ok)
+	if err != nil {
+		s.Unlock()
+		return nil, err
+	}
+	item := c.newItem(key, value, duration, false)
+	item.Tags = cur.Tags
+	item.Priority = cur.Priority
+	if duration == KeepTTL && ok {
+		item.Expired = cur.Expired
+		item.Sliding = cur.Sliding
+	}
+	if err := c.storePut(context.Background(), key, item); err != nil {
+		s.Unlock()
+		return nil, err
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return value, nil
+}
@@ -0,0 +1,399 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Option func(*Cache)
+
+// WithMaxEntries limits the cache to n entries, evicting the least recently
+// used entry (or per WithPolicy) when a Set would exceed the limit.
+// n <= 0 means unbounded. With WithShards the limit is split evenly across
+// shards, so eviction is per shard.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithPolicy selects which entry is evicted once WithMaxEntries is reached.
+func WithPolicy(p Policy) Option {
+	return func(c *Cache) {
+		c.policyKind = p
+	}
+}
+
+// WithAdmission guards a cache bounded by WithMaxEntries or WithMaxCost
+// with a TinyLFU admission filter: once a shard is full, a Set of a new key
+// is rejected with ErrRejected unless the key has been read or written more
+// often than the entry that would be evicted for it.
+func WithAdmission() Option {
+	return func(c *Cache) {
+		c.admission = true
+	}
+}
+
+// WithEvictionPolicy replaces the built-in policy selected by WithPolicy
+// with instances created by newPolicy, one per shard and priority level.
+func WithEvictionPolicy(newPolicy func() EvictionPolicy) Option {
+	return func(c *Cache) {
+		c.policyFactory = newPolicy
+	}
+}
+
+// WithGCBudget limits each GC cycle to removing at most n expired entries,
+// spreading the work of a large expiry wave over several cycles so that no
+// single cycle holds shard locks for long. Entries left over stay invisible
+// to readers until removed. n <= 0 means no limit, the default.
+// DeleteExpired ignores the budget.
+func WithGCBudget(n int) Option {
+	return func(c *Cache) {
+		c.gcBudget = n
+	}
+}
+
+// WithAdaptiveGC lets the cleanup interval adjust itself between lo and hi:
+// it shortens while GC cycles remove many entries or fall behind their
+// WithGCBudget, and lengthens while they find nothing expired. If New is
+// given no cleanup interval, GC starts at hi.
+func WithAdaptiveGC(lo, hi time.Duration) Option {
+	return func(c *Cache) {
+		if lo > 0 && hi >= lo {
+			c.gcMin, c.gcMax = lo, hi
+		}
+	}
+}
+
+// WithLazyExpiry makes Get and GetItem remove an expired entry they come
+// across instead of leaving it for the next GC cycle, so Count catches up
+// sooner. Entries within the WithStaleWhileRevalidate window are kept.
+func WithLazyExpiry() Option {
+	return func(c *Cache) {
+		c.lazyExpiry = true
+	}
+}
+
+// WithOnGCComplete registers fn to be called with a report after every GC
+// cycle, including those run by DeleteExpired. fn runs on the GC goroutine
+// without any lock held.
+func WithOnGCComplete(fn func(GCReport)) Option {
+	return func(c *Cache) {
+		c.onGCComplete = fn
+	}
+}
+
+// WithShutdownHook registers fn to be run by Shutdown once queued writes
+// are flushed, for work such as saving the cache to disk. Hooks run in the
+// order they were added, with Shutdown's ctx.
+func WithShutdownHook(fn func(ctx context.Context, c *Cache) error) Option {
+	return func(c *Cache) {
+		c.shutdownHooks = append(c.shutdownHooks, fn)
+	}
+}
+
+// WithAutoSnapshot saves the cache to path every interval, writing a
+// temporary file and renaming it into place so that path always holds a
+// complete snapshot. New loads the snapshot at path, if there is one, so a
+// restarted process resumes from the last one taken. Failures are reported
+// through WithLogger.
+func WithAutoSnapshot(interval time.Duration, path string) Option {
+	return func(c *Cache) {
+		if interval > 0 && path != "" {
+			c.snapshotInterval = interval
+			c.snapshotPath = path
+		}
+	}
+}
+
+// WithSnapshotStore is like WithAutoSnapshot but ships every snapshot to
+// store with SaveSnapshot, and New loads the newest one with
+// LoadLatestSnapshot.
+func WithSnapshotStore(interval time.Duration, store SnapshotStore) Option {
+	return func(c *Cache) {
+		if interval > 0 && store != nil {
+			c.snapshotInterval = interval
+			c.snapshotStore = store
+		}
+	}
+}
+
+// WithSnapshotRetention keeps the last n scheduled snapshots: as path,
+// path.1, ... path.<n-1> with WithAutoSnapshot, or by deleting older ones
+// from a SnapshotStore that implements SnapshotDeleter. If the newest
+// cannot be read at startup the next older one is loaded. Defaults to 1.
+func WithSnapshotRetention(n int) Option {
+	return func(c *Cache) {
+		c.snapshotKeep = n
+	}
+}
+
+// WithSnapshotEncryption encrypts snapshots written by Save, and so by
+// SaveFile, WithAutoSnapshot, SaveSnapshot and Compact, with AES-GCM under
+// key, which must be 16, 24 or 32 bytes long. Load then rejects snapshots
+// that were tampered with. The append log itself is not encrypted.
+func WithSnapshotEncryption(key []byte) Option {
+	return func(c *Cache) {
+		c.snapshotKey = append([]byte(nil), key...)
+	}
+}
+
+// WithBroadcaster connects the cache to the caches of other instances:
+// every write, delete or Flush made through the cache's API that changes
+// something publishes an invalidation through b, and invalidations
+// published by the others remove the same keys here. Expiry, eviction and
+// Load are not published. Use it to keep near caches in front of a shared
+// store coherent.
+func WithBroadcaster(b Broadcaster) Option {
+	return func(c *Cache) {
+		c.bcast = newBroadcaster(b)
+	}
+}
+
+// WithChangeLog records every mutation from the start for Changes and
+// keeps the last n of them for consumers to resume from. Without it,
+// recording starts with the first call to Changes and keeps 1024.
+func WithChangeLog(n int) Option {
+	return func(c *Cache) {
+		c.changeRetention = n
+	}
+}
+
+// WithShards splits storage into n independently locked shards to reduce
+// lock contention under concurrent load.
+func WithShards(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.shardCount = n
+		}
+	}
+}
+
+// WithOnEvicted registers fn to be called whenever an entry leaves the
+// cache: on Delete, expiry and capacity eviction. fn runs without any lock
+// held and may call back into the cache.
+func WithOnEvicted(fn func(key string, value interface{})) Option {
+	return func(c *Cache) {
+		c.onEvicted = fn
+	}
+}
+
+// WithFlushOnClose makes Close pass every remaining entry to the OnEvicted
+// callback.
+func WithFlushOnClose() Option {
+	return func(c *Cache) {
+		c.flushOnClose = true
+	}
+}
+
+// WithStrictSet restores the original Set behaviour of failing when the key
+// already holds a live entry, for callers that relied on it.
+func WithStrictSet() Option {
+	return func(c *Cache) {
+		c.strictSet = true
+	}
+}
+
+// WithSlidingExpiration makes every entry with a lifetime behave as if it
+// was stored with SetSliding.
+func WithSlidingExpiration() Option {
+	return func(c *Cache) {
+		c.sliding = true
+	}
+}
+
+// WithDefaultLifetime overrides the lifetime used when Set is called with a
+// zero duration. It is mostly useful for Bucket.
+func WithDefaultLifetime(d time.Duration) Option {
+	return func(c *Cache) {
+		c.defaultLifetime = d
+	}
+}
+
+// WithTTLRule gives keys matching pattern, in the syntax of KeysMatching,
+// a default lifetime of d in place of the cache-wide one. Rules are tried
+// in the order they were added and the first match wins.
+func WithTTLRule(pattern string, d time.Duration) Option {
+	return func(c *Cache) {
+		c.ttlRules = append(c.ttlRules, ttlRule{pattern: pattern, lifetime: d})
+	}
+}
+
+// WithTTLJitter randomizes each entry's lifetime by up to fraction of it in
+// either direction, so that entries stored together, such as when warming
+// the cache, do not all expire at once. fraction should be between 0 and 1.
+// Deadlines given with SetWithDeadline or ExpireAt are kept exactly.
+func WithTTLJitter(fraction float64) Option {
+	return func(c *Cache) {
+		c.ttlJitter = fraction
+	}
+}
+
+// WithWarmup keeps the cache's Ready channel open until a call to Warm
+// completes, so that health checks can wait for a hot cache. Reads and
+// writes work as usual in the meantime.
+func WithWarmup() Option {
+	return func(c *Cache) {
+		c.warmup = true
+	}
+}
+
+// Weigher estimates the memory cost of an entry in bytes.
+type Weigher func(key string, value interface{}) int64
+
+// DefaultWeigher counts the key plus the length of string and []byte values;
+// lists and the other data-structure values are charged per element, and
+// other values a flat 16 bytes.
+func DefaultWeigher(key string, value interface{}) int64 {
+	n := int64(len(key))
+	switch v := value.(type) {
+	case string:
+		return n + int64(len(v))
+	case []byte:
+		return n + int64(len(v))
+	case container:
+		return n + v.weight()
+	default:
+		return n + 16
+	}
+}
+
+// WithMaxCost bounds the total weight of all entries, as measured by the
+// Weigher, evicting entries per WithPolicy to stay under it. Like
+// WithMaxEntries the budget is split evenly across shards. A single entry
+// heavier than a shard's budget is rejected by Set.
+func WithMaxCost(bytes int64) Option {
+	return func(c *Cache) {
+		c.maxCost = bytes
+	}
+}
+
+// WithWeigher sets the function used to cost entries for WithMaxCost. It
+// defaults to DefaultWeigher.
+func WithWeigher(w Weigher) Option {
+	return func(c *Cache) {
+		c.weigher = w
+	}
+}
+
+// WithClock replaces the wall clock used for expiry and for scheduling GC
+// and compaction, e.g. with a FakeClock in tests.
+func WithClock(clock Clock) Option {
+	return func(c *Cache) {
+		c.clock = clock
+	}
+}
+
+// WithCodec sets the codec GetInto uses to copy values. It defaults to
+// GobCodec.
+func WithCodec(codec Codec) Option {
+	return func(c *Cache) {
+		c.codec = codec
+	}
+}
+
+// WithValueCopy stores and returns deep copies of values, made with codec,
+// so callers mutating a map or slice after Set or after Get cannot change
+// what the cache holds. Values the codec cannot encode are stored and
+// returned as is.
+func WithValueCopy(codec Codec) Option {
+	return func(c *Cache) {
+		c.valueCodec = codec
+	}
+}
+
+// WithLoader makes Get and Fetch call l on a miss and cache what it
+// returns. Concurrent misses on a key share a single Load call.
+func WithLoader(l Loader) Option {
+	return func(c *Cache) {
+		c.loader = l
+	}
+}
+
+// WithStaleWhileRevalidate keeps expired entries for window, during which
+// Get and Fetch return them while the Loader refreshes the key in the
+// background. It has no effect without WithLoader.
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(c *Cache) {
+		c.staleWindow = window
+	}
+}
+
+// WithUndoWindow lets UndoDelete restore an entry for d after DeleteAfter
+// removed it.
+func WithUndoWindow(d time.Duration) Option {
+	return func(c *Cache) {
+		c.undoWindow = d
+	}
+}
+
+// WithTombstones keeps a Tombstone for every deleted, expired or evicted
+// entry for retention, readable with GetTombstone.
+func WithTombstones(retention time.Duration) Option {
+	return func(c *Cache) {
+		c.tombstoneRetention = retention
+	}
+}
+
+// WithRefreshAhead makes Get and Fetch reload a key in the background once
+// less than fraction of its lifetime remains, so frequently read keys are
+// replaced before they expire. It has no effect without WithLoader.
+func WithRefreshAhead(fraction float64) Option {
+	return func(c *Cache) {
+		c.refreshAhead = fraction
+	}
+}
+
+// WithNegativeCaching caches Loader results that wrap ErrKeyNotFound as
+// negative entries for ttl, so repeated lookups of a missing record do not
+// reach the Loader again until ttl elapses. ttl is also SetNegative's
+// default.
+func WithNegativeCaching(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithLogger logs GC runs and evictions at debug level, and errors that
+// would otherwise go unreported, such as failed append log writes and
+// background refreshes, write-behind failures without an OnError handler
+// and panics in the OnEvicted callback, which are then recovered.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Cache) {
+		c.logger = logger
+	}
+}
+
+// WithWriteThrough writes every change made through the cache's API, from
+// Set and Delete to Increment, Rename and the list, hash and set
+// operations, to store before applying it to the cache. If the store
+// fails, the cache is left unchanged and the store's error is returned.
+// Expiry, eviction, Flush, entries restored by Load or ImportLocal and
+// broadcast invalidations are not propagated.
+func WithWriteThrough(store Store) Option {
+	return func(c *Cache) {
+		c.store = store
+		c.behind = nil
+	}
+}
+
+// WithWriteBehind applies writes to the cache immediately and propagates
+// them to store in the background, coalescing repeated writes to a key and
+// retrying failures as configured. Close flushes pending writes.
+func WithWriteBehind(store Store, cfg WriteBehindConfig) Option {
+	return func(c *Cache) {
+		c.store = store
+		c.behind = newWriteBehind(store, cfg)
+	}
+}
+
+// WithCompression transparently compresses string and []byte values of at
+// least minSize bytes with compressor. Values are decompressed on read, and
+// cost accounting uses the compressed size.
+func WithCompression(compressor Compressor, minSize int) Option {
+	return func(c *Cache) {
+		c.compressor = compressor
+		c.compressMin = minSize
+	}
+}
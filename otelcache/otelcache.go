@@ -0,0 +1,176 @@
+// Package otelcache wraps a cache so that every operation is recorded as an
+// OpenTelemetry span carrying the key, hit or miss and value size; the
+// span's duration is the operation's latency. Spans are children of the
+// span in the caller's context, and Loader wraps a cache.Loader so that
+// loads triggered by a miss appear in the same trace.
+package otelcache
+
+import (
+	"context"
+	"time"
+
+	cache "go-in-memory-cache"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scope = "go-in-memory-cache/otelcache"
+
+var (
+	keyAttr  = attribute.Key("cache.key")
+	hitAttr  = attribute.Key("cache.hit")
+	sizeAttr = attribute.Key("cache.value_size")
+	ttlAttr  = attribute.Key("cache.ttl_ms")
+)
+
+type config struct {
+	provider trace.TracerProvider
+	noKeys   bool
+}
+
+type Option func(*config)
+
+// WithTracerProvider selects the provider spans are created from. It
+// defaults to the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = tp
+	}
+}
+
+// WithoutKeys leaves the key out of span attributes, for caches whose keys
+// are sensitive.
+func WithoutKeys() Option {
+	return func(c *config) {
+		c.noKeys = true
+	}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{provider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+type Cache struct {
+	cache  *cache.Cache
+	tracer trace.Tracer
+	noKeys bool
+}
+
+func New(c *cache.Cache, opts ...Option) *Cache {
+	cfg := newConfig(opts)
+	return &Cache{cache: c, tracer: cfg.provider.Tracer(scope), noKeys: cfg.noKeys}
+}
+
+// Unwrap returns the underlying cache.
+func (c *Cache) Unwrap() *cache.Cache {
+	return c.cache
+}
+
+func (c *Cache) start(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	var attrs []attribute.KeyValue
+	if !c.noKeys {
+		attrs = append(attrs, keyAttr.String(key))
+	}
+	return c.tracer.Start(ctx, "cache."+op, trace.WithAttributes(attrs...), trace.WithSpanKind(trace.SpanKindInternal))
+}
+
+func (c *Cache) Get(ctx context.Context, key string) (interface{}, bool) {
+	ctx, span := c.start(ctx, "get", key)
+	defer span.End()
+
+	value, ok := c.cache.GetCtx(ctx, key)
+	span.SetAttributes(hitAttr.Bool(ok))
+	if ok {
+		setSize(span, value)
+	}
+	return value, ok
+}
+
+func (c *Cache) Fetch(ctx context.Context, key string) (interface{}, error) {
+	ctx, span := c.start(ctx, "fetch", key)
+	defer span.End()
+
+	value, err := c.cache.Fetch(ctx, key)
+	if err != nil {
+		fail(span, err)
+		return nil, err
+	}
+	setSize(span, value)
+	return value, nil
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, duration time.Duration) error {
+	ctx, span := c.start(ctx, "set", key)
+	defer span.End()
+
+	span.SetAttributes(ttlAttr.Int64(duration.Milliseconds()))
+	setSize(span, value)
+	if err := c.cache.SetCtx(ctx, key, value, duration); err != nil {
+		fail(span, err)
+		return err
+	}
+	return nil
+}
+
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	ctx, span := c.start(ctx, "delete", key)
+	defer span.End()
+
+	if err := c.cache.DeleteCtx(ctx, key); err != nil {
+		fail(span, err)
+		return err
+	}
+	return nil
+}
+
+type loader struct {
+	next   cache.Loader
+	tracer trace.Tracer
+	noKeys bool
+}
+
+// Loader wraps l so that every load is recorded as a span, a child of the
+// span in the context of the Get or Fetch that missed.
+func Loader(l cache.Loader, opts ...Option) cache.Loader {
+	cfg := newConfig(opts)
+	return &loader{next: l, tracer: cfg.provider.Tracer(scope), noKeys: cfg.noKeys}
+}
+
+func (l *loader) Load(ctx context.Context, key string) (interface{}, time.Duration, error) {
+	var attrs []attribute.KeyValue
+	if !l.noKeys {
+		attrs = append(attrs, keyAttr.String(key))
+	}
+	ctx, span := l.tracer.Start(ctx, "cache.load", trace.WithAttributes(attrs...))
+	defer span.End()
+
+	value, ttl, err := l.next.Load(ctx, key)
+	if err != nil {
+		fail(span, err)
+		return nil, 0, err
+	}
+	setSize(span, value)
+	span.SetAttributes(ttlAttr.Int64(ttl.Milliseconds()))
+	return value, ttl, nil
+}
+
+func setSize(span trace.Span, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		span.SetAttributes(sizeAttr.Int(len(v)))
+	case []byte:
+		span.SetAttributes(sizeAttr.Int(len(v)))
+	}
+}
+
+func fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,154 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, Option) {
+	sr := tracetest.NewSpanRecorder()
+	return sr, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+}
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestSpans(t *testing.T) {
+	errLoad := errors.New("load failed")
+	tests := []struct {
+		name      string
+		op        func(ctx context.Context, c *Cache)
+		span      string
+		want      map[attribute.Key]attribute.Value
+		wantError bool
+	}{
+		{
+			name: "get hit",
+			op:   func(ctx context.Context, c *Cache) { c.Get(ctx, "a") },
+			span: "cache.get",
+			want: map[attribute.Key]attribute.Value{
+				keyAttr:  attribute.StringValue("a"),
+				hitAttr:  attribute.BoolValue(true),
+				sizeAttr: attribute.IntValue(5),
+			},
+		},
+		{
+			name: "get miss",
+			op:   func(ctx context.Context, c *Cache) { c.Get(ctx, "missing") },
+			span: "cache.get",
+			want: map[attribute.Key]attribute.Value{
+				keyAttr: attribute.StringValue("missing"),
+				hitAttr: attribute.BoolValue(false),
+			},
+		},
+		{
+			name: "set",
+			op:   func(ctx context.Context, c *Cache) { c.Set(ctx, "b", []byte("xyz"), 2*time.Second) },
+			span: "cache.set",
+			want: map[attribute.Key]attribute.Value{
+				keyAttr:  attribute.StringValue("b"),
+				ttlAttr:  attribute.Int64Value(2000),
+				sizeAttr: attribute.IntValue(3),
+			},
+		},
+		{
+			name:      "delete missing",
+			op:        func(ctx context.Context, c *Cache) { c.Delete(ctx, "missing") },
+			span:      "cache.delete",
+			want:      map[attribute.Key]attribute.Value{keyAttr: attribute.StringValue("missing")},
+			wantError: true,
+		},
+		{
+			name:      "fetch error",
+			op:        func(ctx context.Context, c *Cache) { c.Fetch(ctx, "fail") },
+			span:      "cache.fetch",
+			want:      map[attribute.Key]attribute.Value{keyAttr: attribute.StringValue("fail")},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr, opt := newRecorder()
+			inner := cache.New(0, 0, cache.WithLoader(cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+				return nil, 0, errLoad
+			})))
+			defer inner.Close()
+			if err := inner.Set("a", "hello", 0); err != nil {
+				t.Fatal(err)
+			}
+			c := New(inner, opt)
+
+			tt.op(context.Background(), c)
+			var span sdktrace.ReadOnlySpan
+			for _, s := range sr.Ended() {
+				if s.Name() == tt.span {
+					span = s
+				}
+			}
+			if span == nil {
+				t.Fatalf("no %s span among %d", tt.span, len(sr.Ended()))
+			}
+			if got := attrs(span); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("attributes = %v, want %v", got, tt.want)
+			}
+			if got := span.Status().Code == codes.Error; got != tt.wantError {
+				t.Errorf("span status %v, want error %v", span.Status(), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestWithoutKeys(t *testing.T) {
+	sr, opt := newRecorder()
+	c := New(cache.New(0, 0), opt, WithoutKeys())
+	c.Get(context.Background(), "secret")
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	if _, ok := attrs(spans[0])[keyAttr]; ok {
+		t.Error("span carries the key")
+	}
+}
+
+func TestLoaderSpanIsChild(t *testing.T) {
+	sr, opt := newRecorder()
+	l := Loader(cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		return "loaded", time.Minute, nil
+	}), opt)
+	c := New(cache.New(0, 0, cache.WithLoader(l)), opt)
+
+	if v, err := c.Fetch(context.Background(), "k"); err != nil || v != "loaded" {
+		t.Fatalf("Fetch = %v, %v; want loaded, nil", v, err)
+	}
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range sr.Ended() {
+		spans[s.Name()] = s
+	}
+	load, fetch := spans["cache.load"], spans["cache.fetch"]
+	if load == nil || fetch == nil {
+		t.Fatalf("recorded spans %v, want cache.load and cache.fetch", spans)
+	}
+	if load.Parent().SpanID() != fetch.SpanContext().SpanID() {
+		t.Error("load span is not a child of the fetch span")
+	}
+	if got := attrs(load)[ttlAttr]; got != attribute.Int64Value(60000) {
+		t.Errorf("load TTL attribute = %v, want 60000", got)
+	}
+}
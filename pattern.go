@@ -0,0 +1,107 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"strings"
+)
+
+// KeysWithPrefix returns the keys of live entries that start with prefix.
+func (c *Cache) KeysWithPrefix(prefix string) []string {
+	return c.keysWhere(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// KeysMatching returns the keys of live entries matching a glob pattern,
+// where '*' matches any run of characters, '?' matches a single character
+// and '\' escapes the next character.
+func (c *Cache) KeysMatching(pattern string) []string {
+	return c.keysWhere(func(key string) bool {
+		return matchGlob(pattern, key)
+	})
+}
+
+// DeleteByPrefix removes every entry whose key starts with prefix and
+// returns how many were removed.
+func (c *Cache) DeleteByPrefix(prefix string) int {
+	return c.deleteWhere(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// DeleteMatching removes every entry whose key matches a glob pattern (see
+// KeysMatching) and returns how many were removed.
+func (c *Cache) DeleteMatching(pattern string) int {
+	return c.deleteWhere(func(key string) bool {
+		return matchGlob(pattern, key)
+	})
+}
+
+func (c *Cache) keysWhere(match func(key string) bool) []string {
+	var keys []string
+	c.Range(func(key string, _ Item) bool {
+		if match(key) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	return keys
+}
+
+func (c *Cache) deleteWhere(match func(key string) bool) int {
+	if c.writable() != nil {
+		return 0
+	}
+
+	var removed []keyedItem
+	for _, s := range c.shards {
+		s.Lock()
+		for key := range s.items {
+			if !match(key) {
+				continue
+			}
+			if _, ok := s.live(key); !ok || c.storeDelete(context.Background(), key) != nil {
+				continue
+			}
+			if item, ok := s.remove(key); ok {
+				removed = append(removed, keyedItem{key: key, item: item})
+			}
+		}
+		s.Unlock()
+	}
+
+	c.deleted(removed)
+	c.invalidate(keysOf(removed)...)
+	return len(removed)
+}
+
+func matchGlob(pattern, s string) bool {
+	var p, i int
+	star, mark := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, i
+			p++
+			continue
+		case p < len(pattern) && pattern[p] == '\\' && p+1 < len(pattern) && pattern[p+1] == s[i]:
+			p += 2
+			i++
+			continue
+		case p < len(pattern) && (pattern[p] == '?' || (pattern[p] != '\\' && pattern[p] == s[i])):
+			p++
+			i++
+			continue
+		}
+		if star < 0 {
+			return false
+		}
+		p = star + 1
+		mark++
+		i = mark
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,238 @@
+package go_in_memory_cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Snapshots written by Save start with a header: snapshotMagic, the
+// format version and a flags byte. The payload follows, either encrypted
+// with AES-GCM or followed by its SHA-256 checksum. Version 0 files are
+// bare gob maps of Item with no header; version 1 files had the magic and
+// flags but no version byte.
+const (
+	snapshotMagic   = "GIMC"
+	snapshotVersion = 2
+)
+
+const (
+	snapshotEncrypted byte = 1 << iota
+)
+
+// snapshotEntry is how version 2 snapshots encode an entry. It is kept
+// separate from Item so that Item can change without breaking old files;
+// when it must change, add a new version and a loader for it.
+type snapshotEntry struct {
+	Key      string
+	Value    interface{}
+	Created  time.Time
+	Expired  int64
+	Sliding  time.Duration
+	Tags     []string
+	Priority Priority
+	Negative bool
+}
+
+// snapshotLoaders decode the payload of each format version.
+var snapshotLoaders = map[byte]func(payload []byte) (map[string]Item, error){
+	0: loadSnapshotV0,
+	1: loadSnapshotV0,
+	2: loadSnapshotV2,
+}
+
+func loadSnapshotV0(payload []byte) (map[string]Item, error) {
+	items := make(map[string]Item)
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func loadSnapshotV2(payload []byte) (map[string]Item, error) {
+	var entries []snapshotEntry
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&entries); err != nil {
+		return nil, err
+	}
+	items := make(map[string]Item, len(entries))
+	for _, e := range entries {
+		items[e.Key] = Item{
+			Value:    e.Value,
+			Created:  e.Created,
+			Expired:  e.Expired,
+			Sliding:  e.Sliding,
+			Tags:     e.Tags,
+			Priority: e.Priority,
+			Negative: e.Negative,
+		}
+	}
+	return items, nil
+}
+
+// Save writes all live entries to w using gob. Values of custom types must
+// be registered with gob.Register before saving and loading. With
+// WithSnapshotEncryption the snapshot is encrypted and authenticated;
+// otherwise it carries a checksum.
+func (c *Cache) Save(w io.Writer) error {
+	if c.closed.Load() {
+		return ErrCacheClosed
+	}
+
+	var entries []snapshotEntry
+	for key, item := range c.Items() {
+		entries = append(entries, snapshotEntry{
+			Key:      key,
+			Value:    item.Value,
+			Created:  item.Created,
+			Expired:  item.Expired,
+			Sliding:  item.Sliding,
+			Tags:     item.Tags,
+			Priority: item.Priority,
+			Negative: item.Negative,
+		})
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entries); err != nil {
+		return err
+	}
+
+	header := []byte(snapshotMagic + string([]byte{snapshotVersion, 0}))
+	payload := buf.Bytes()
+	if c.snapshotKey != nil {
+		header[len(header)-1] |= snapshotEncrypted
+		aead, err := newSnapshotAEAD(c.snapshotKey)
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		payload = aead.Seal(nonce, nonce, payload, header)
+	} else {
+		sum := sha256.Sum256(payload)
+		payload = append(payload, sum[:]...)
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func newSnapshotAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// splitSnapshot separates a snapshot into its header fields and payload.
+func splitSnapshot(data []byte) (version, flags byte, header, payload []byte, err error) {
+	n := len(snapshotMagic)
+	switch {
+	case !bytes.HasPrefix(data, []byte(snapshotMagic)):
+		return 0, 0, nil, data, nil
+	case len(data) > n && data[n] < 2:
+		return 1, data[n], data[:n+1], data[n+1:], nil
+	case len(data) > n+1:
+		return data[n], data[n+1], data[:n+2], data[n+2:], nil
+	}
+	return 0, 0, nil, nil, ErrSnapshotCorrupt
+}
+
+// decodeSnapshot verifies, decrypts and decodes a snapshot written by Save
+// in any supported format version.
+func (c *Cache) decodeSnapshot(data []byte) (map[string]Item, error) {
+	version, flags, header, payload, err := splitSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+	load, ok := snapshotLoaders[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported snapshot format version %d", version)
+	}
+	if flags&^snapshotEncrypted != 0 {
+		return nil, fmt.Errorf("unsupported snapshot flags %#x", flags)
+	}
+
+	switch {
+	case version == 0:
+	case flags&snapshotEncrypted == 0:
+		if len(payload) < sha256.Size {
+			return nil, ErrSnapshotCorrupt
+		}
+		var sum []byte
+		payload, sum = payload[:len(payload)-sha256.Size], payload[len(payload)-sha256.Size:]
+		if want := sha256.Sum256(payload); !bytes.Equal(sum, want[:]) {
+			return nil, ErrSnapshotCorrupt
+		}
+	default:
+		if c.snapshotKey == nil {
+			return nil, ErrSnapshotEncrypted
+		}
+		aead, err := newSnapshotAEAD(c.snapshotKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) < aead.NonceSize() {
+			return nil, ErrSnapshotCorrupt
+		}
+		nonce, sealed := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+		if payload, err = aead.Open(nil, nonce, sealed, header); err != nil {
+			return nil, ErrSnapshotCorrupt
+		}
+	}
+	return load(payload)
+}
+
+func (c *Cache) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads entries written by Save. Entries that have expired in the
+// meantime are skipped, and keys that already hold a live value are left
+// untouched.
+func (c *Cache) Load(r io.Reader) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	items, err := c.decodeSnapshot(data)
+	if err != nil {
+		return err
+	}
+
+	c.importItems(items, false, false)
+	return nil
+}
+
+func (c *Cache) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
@@ -0,0 +1,106 @@
+package go_in_memory_cache
+
+import "context"
+
+type pinOptions struct {
+	noExpire bool
+}
+
+type PinOption func(*pinOptions)
+
+// PinNoExpire makes a pinned entry never expire. Its lifetime is cleared,
+// and stays cleared after Unpin.
+func PinNoExpire() PinOption {
+	return func(o *pinOptions) { o.noExpire = true }
+}
+
+// Pin exempts the live entry under key from eviction by the capacity
+// policy. The pin survives overwrites and is dropped when the key is
+// deleted, expires or goes through Rename. Pinned entries still count
+// towards capacity, so a shard holding only pinned entries may exceed it.
+func (c *Cache) Pin(key string, opts ...PinOption) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+	var o pinOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+
+	item, ok := s.live(key)
+	if !ok {
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+	rewrite := o.noExpire && (item.Expired != 0 || item.Sliding != 0)
+	if rewrite {
+		// The lifetime change is a write like any other: it goes to the
+		// backing store, and store logs it and notifies watchers.
+		item.Expired, item.Sliding = 0, 0
+		if err := c.storePut(context.Background(), key, item); err != nil {
+			s.Unlock()
+			return err
+		}
+	}
+	if s.pinned == nil {
+		s.pinned = make(map[string]bool)
+	}
+	s.pinned[key] = s.pinned[key] || o.noExpire
+	s.trackRemove(key)
+	var evicted []keyedItem
+	if rewrite {
+		evicted = s.store(key, item)
+	}
+	s.Unlock()
+
+	c.overflowed(evicted)
+	if rewrite {
+		c.invalidate(key)
+	}
+	return nil
+}
+
+// Unpin makes key subject to eviction again. Unpinning a key that is not
+// pinned does nothing.
+func (c *Cache) Unpin(key string) {
+	if c.writable() != nil {
+		return
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.pinned[key]; !ok {
+		return
+	}
+	delete(s.pinned, key)
+	if item, ok := s.items[key]; ok {
+		s.trackAdd(key, item.Priority)
+	}
+}
+
+// Pinned reports whether key is pinned.
+func (c *Cache) Pinned(key string) bool {
+	if c.closed.Load() {
+		return false
+	}
+	s := c.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+	_, ok := s.pinned[key]
+	return ok
+}
+
+func (c *Cache) pinnedCount() int {
+	var n int
+	for _, s := range c.shards {
+		s.RLock()
+		n += len(s.pinned)
+		s.RUnlock()
+	}
+	return n
+}
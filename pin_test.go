@@ -0,0 +1,55 @@
+package go_in_memory_cache
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPinNoExpire(t *testing.T) {
+	store := &recordingStore{}
+	c := New(0, 0, WithClock(NewFakeClock(epoch)), WithWriteThrough(store))
+	if err := c.Set("key", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	store.take()
+	events, cancel := c.Watch("key")
+	defer cancel()
+
+	if err := c.Pin("key", PinNoExpire()); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := c.TTL("key"); d != NoExpiration {
+		t.Errorf("TTL = %v, want NoExpiration", d)
+	}
+	if got := store.take(); !reflect.DeepEqual(got, []string{"put key=1"}) {
+		t.Errorf("store saw %q, want [put key=1]", got)
+	}
+	select {
+	case e := <-events:
+		if e.Type != EventSet {
+			t.Errorf("watcher got %v, want EventSet", e.Type)
+		}
+	case <-time.After(time.Second):
+		t.Error("watcher was not notified")
+	}
+}
+
+func TestPinFrozen(t *testing.T) {
+	c := New(0, 0, WithClock(NewFakeClock(epoch)))
+	if err := c.Set("key", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	c.Freeze()
+
+	if err := c.Pin("key", PinNoExpire()); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Pin = %v, want %v", err, ErrFrozen)
+	}
+	if c.Pinned("key") {
+		t.Error("frozen cache pinned key")
+	}
+	if d, _ := c.TTL("key"); d != time.Minute {
+		t.Errorf("TTL = %v, want 1m", d)
+	}
+}
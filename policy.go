@@ -0,0 +1,196 @@
+package go_in_memory_cache
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+type Policy int
+
+const (
+	LRU Policy = iota
+	LFU
+	FIFO
+)
+
+// EvictionPolicy tracks key usage and picks victims when the cache is full.
+// Every shard, and every priority level within it, gets its own instance.
+// Implementations need not be safe for concurrent use; the cache serializes
+// calls.
+type EvictionPolicy interface {
+	// OnAdd is called when key is stored, including overwrites.
+	OnAdd(key string)
+	// OnAccess is called when key is read.
+	OnAccess(key string)
+	// OnRemove is called when key leaves the cache for any reason,
+	// including after being chosen by Victim.
+	OnRemove(key string)
+	// Victim returns the key to evict next, which must be one that was
+	// added and not yet removed, or false if there is none.
+	Victim() (string, bool)
+}
+
+func newPolicy(p Policy) EvictionPolicy {
+	switch p {
+	case LFU:
+		return newLFUHeap()
+	case FIFO:
+		return newFIFOQueue()
+	default:
+		return newLRUList()
+	}
+}
+
+type lruList struct {
+	ll       *list.List
+	elements map[string]*list.Element
+}
+
+func newLRUList() *lruList {
+	return &lruList{
+		ll:       list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+func (l *lruList) OnAdd(key string) {
+	if e, ok := l.elements[key]; ok {
+		l.ll.MoveToFront(e)
+		return
+	}
+	l.elements[key] = l.ll.PushFront(key)
+}
+
+func (l *lruList) OnAccess(key string) {
+	if e, ok := l.elements[key]; ok {
+		l.ll.MoveToFront(e)
+	}
+}
+
+func (l *lruList) OnRemove(key string) {
+	if e, ok := l.elements[key]; ok {
+		l.ll.Remove(e)
+		delete(l.elements, key)
+	}
+}
+
+func (l *lruList) Victim() (string, bool) {
+	e := l.ll.Back()
+	if e == nil {
+		return "", false
+	}
+	return e.Value.(string), true
+}
+
+type fifoQueue struct {
+	lruList
+}
+
+func newFIFOQueue() *fifoQueue {
+	return &fifoQueue{lruList: *newLRUList()}
+}
+
+func (q *fifoQueue) OnAdd(key string) {
+	if _, ok := q.elements[key]; ok {
+		return
+	}
+	q.elements[key] = q.ll.PushFront(key)
+}
+
+func (q *fifoQueue) OnAccess(string) {}
+
+type lfuEntry struct {
+	key   string
+	hits  uint64
+	seq   uint64
+	index int
+}
+
+type lfuEntries []*lfuEntry
+
+func (h lfuEntries) Len() int { return len(h) }
+
+func (h lfuEntries) Less(i, j int) bool {
+	if h[i].hits != h[j].hits {
+		return h[i].hits < h[j].hits
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h lfuEntries) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuEntries) Push(x interface{}) {
+	e := x.(*lfuEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuEntries) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+// lfuHeap evicts the entry with the fewest hits, breaking ties by least
+// recent access. New entries start from the sketch estimate so that keys
+// which were popular before being evicted are not treated as brand new.
+type lfuHeap struct {
+	entries lfuEntries
+	byKey   map[string]*lfuEntry
+	sketch  *frequencySketch
+	seq     uint64
+}
+
+func newLFUHeap() *lfuHeap {
+	return &lfuHeap{
+		byKey:  make(map[string]*lfuEntry),
+		sketch: newFrequencySketch(1024),
+	}
+}
+
+func (l *lfuHeap) OnAdd(key string) {
+	l.seq++
+	l.sketch.increment(key)
+	if e, ok := l.byKey[key]; ok {
+		e.hits++
+		e.seq = l.seq
+		heap.Fix(&l.entries, e.index)
+		return
+	}
+	e := &lfuEntry{key: key, hits: uint64(l.sketch.estimate(key)), seq: l.seq}
+	l.byKey[key] = e
+	heap.Push(&l.entries, e)
+}
+
+func (l *lfuHeap) OnAccess(key string) {
+	e, ok := l.byKey[key]
+	if !ok {
+		return
+	}
+	l.seq++
+	l.sketch.increment(key)
+	e.hits++
+	e.seq = l.seq
+	heap.Fix(&l.entries, e.index)
+}
+
+func (l *lfuHeap) OnRemove(key string) {
+	if e, ok := l.byKey[key]; ok {
+		heap.Remove(&l.entries, e.index)
+		delete(l.byKey, key)
+	}
+}
+
+func (l *lfuHeap) Victim() (string, bool) {
+	if len(l.entries) == 0 {
+		return "", false
+	}
+	return l.entries[0].key, true
+}
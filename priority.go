@@ -0,0 +1,92 @@
+package go_in_memory_cache
+
+import (
+	"slices"
+	"time"
+)
+
+// Priority orders entries for eviction: when a shard is full, entries of
+// the lowest priority present are evicted first, and the eviction policy
+// only decides among entries of equal priority.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// SetWithPriority stores value like Set with the given eviction priority.
+func (c *Cache) SetWithPriority(key string, value interface{}, duration time.Duration, p Priority) error {
+	item := c.newItem(key, value, duration, false)
+	item.Priority = p
+	return c.setItem(key, item, setAlways)
+}
+
+// priorityBuckets keeps one eviction policy per priority level in use.
+// levels lists those priorities in ascending order.
+type priorityBuckets struct {
+	policy  func() EvictionPolicy
+	levels  []Priority
+	buckets map[Priority]EvictionPolicy
+	sizes   map[Priority]int
+	level   map[string]Priority
+}
+
+func newPriorityBuckets(policy func() EvictionPolicy) *priorityBuckets {
+	return &priorityBuckets{
+		policy:  policy,
+		buckets: make(map[Priority]EvictionPolicy),
+		sizes:   make(map[Priority]int),
+		level:   make(map[string]Priority),
+	}
+}
+
+func (b *priorityBuckets) add(key string, p Priority) {
+	if old, ok := b.level[key]; ok && old != p {
+		b.remove(key)
+	} else if ok {
+		b.buckets[p].OnAdd(key)
+		return
+	}
+	bucket, ok := b.buckets[p]
+	if !ok {
+		bucket = b.policy()
+		b.buckets[p] = bucket
+		i, _ := slices.BinarySearch(b.levels, p)
+		b.levels = slices.Insert(b.levels, i, p)
+	}
+	b.level[key] = p
+	b.sizes[p]++
+	bucket.OnAdd(key)
+}
+
+func (b *priorityBuckets) access(key string) {
+	if p, ok := b.level[key]; ok {
+		b.buckets[p].OnAccess(key)
+	}
+}
+
+func (b *priorityBuckets) remove(key string) {
+	p, ok := b.level[key]
+	if !ok {
+		return
+	}
+	delete(b.level, key)
+	b.buckets[p].OnRemove(key)
+	if b.sizes[p]--; b.sizes[p] == 0 {
+		delete(b.buckets, p)
+		delete(b.sizes, p)
+		i, _ := slices.BinarySearch(b.levels, p)
+		b.levels = slices.Delete(b.levels, i, i+1)
+	}
+}
+
+func (b *priorityBuckets) victim() (string, bool) {
+	for _, p := range b.levels {
+		if key, ok := b.buckets[p].Victim(); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
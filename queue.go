@@ -0,0 +1,92 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// queueWaiters wakes DequeueWait callers when their list grows. Each key
+// with waiters has a channel that the next push closes.
+type queueWaiters struct {
+	mu    sync.Mutex
+	waits map[string]*queueWait
+}
+
+type queueWait struct {
+	ch chan struct{}
+	n  int
+}
+
+// wait returns a channel closed by the next push to key. Callers must
+// release it with done once they stop waiting.
+func (q *queueWaiters) wait(key string) *queueWait {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.waits == nil {
+		q.waits = make(map[string]*queueWait)
+	}
+	w := q.waits[key]
+	if w == nil {
+		w = &queueWait{ch: make(chan struct{})}
+		q.waits[key] = w
+	}
+	w.n++
+	return w
+}
+
+func (q *queueWaiters) done(key string, w *queueWait) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	w.n--
+	if w.n == 0 && q.waits[key] == w {
+		delete(q.waits, key)
+	}
+}
+
+func (q *queueWaiters) signal(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w := q.waits[key]; w != nil {
+		close(w.ch)
+		delete(q.waits, key)
+	}
+}
+
+// Enqueue appends value to the queue under key and returns its length. A
+// queue is a list, so the list operations apply to it as well.
+func (c *Cache) Enqueue(key string, value interface{}) (int, error) {
+	return c.RPush(key, value)
+}
+
+// Dequeue removes and returns the oldest element of the queue under key. It
+// fails with ErrKeyNotFound if the queue is empty.
+func (c *Cache) Dequeue(key string) (interface{}, error) {
+	return c.LPop(key)
+}
+
+// DequeueWait is like Dequeue but blocks while the queue is empty, until an
+// element is pushed, ctx is done, or the cache is closed. Each element goes
+// to exactly one caller. Only LPush, RPush and Enqueue wake a waiter; a
+// list stored with Set is seen on the next push.
+func (c *Cache) DequeueWait(ctx context.Context, key string) (interface{}, error) {
+	for {
+		// Register before trying, so a push in between is not missed.
+		w := c.queues.wait(key)
+		v, err := c.Dequeue(key)
+		if !errors.Is(err, ErrKeyNotFound) {
+			c.queues.done(key, w)
+			return v, err
+		}
+		select {
+		case <-w.ch:
+			c.queues.done(key, w)
+		case <-ctx.Done():
+			c.queues.done(key, w)
+			return nil, ctx.Err()
+		case <-c.stop:
+			c.queues.done(key, w)
+			return nil, ErrCacheClosed
+		}
+	}
+}
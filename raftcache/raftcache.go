@@ -0,0 +1,230 @@
+// Package raftcache replicates a cache with the Raft consensus protocol,
+// using github.com/hashicorp/raft. Writes are appended to the Raft log and
+// applied by every replica in log order, so all replicas converge on the
+// same contents. This trades write latency for consistency and suits small,
+// rarely written data such as configuration and feature flags.
+//
+// Each replica wraps its cache in an FSM, starts Raft with it and writes
+// through a Node:
+//
+//	fsm := raftcache.NewFSM(c)
+//	r, err := raft.NewRaft(conf, fsm, logs, stable, snaps, transport)
+//	node := raftcache.New(r, fsm)
+//	err = node.Set(ctx, "flag", true, cache.NoExpiration)
+//
+// Writes must be made on the leader; followers reject them with
+// raft.ErrNotLeader. The wrapped cache must not be written to directly.
+// Expiry deadlines are taken from the leader's clock when the write is
+// appended, so replicas' clocks should agree.
+package raftcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"time"
+
+	cache "go-in-memory-cache"
+
+	"github.com/hashicorp/raft"
+)
+
+type op uint8
+
+const (
+	opSet op = iota + 1
+	opDelete
+)
+
+// command is the payload of a Raft log entry.
+type command struct {
+	Op       op
+	Key      string
+	Value    []byte
+	Duration time.Duration
+}
+
+type config struct {
+	codec cache.Codec
+}
+
+type Option func(*config)
+
+// WithCodec selects how values are encoded in the Raft log. All replicas
+// must use the same codec. It defaults to cache.GobCodec, which requires
+// custom value types to be registered with gob.Register.
+func WithCodec(codec cache.Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+// FSM applies committed log entries to a cache. It implements raft.FSM.
+type FSM struct {
+	cache *cache.Cache
+	cfg   config
+}
+
+func NewFSM(c *cache.Cache, opts ...Option) *FSM {
+	cfg := config{codec: cache.GobCodec}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &FSM{cache: c, cfg: cfg}
+}
+
+// Cache returns the cache f applies entries to. Read from it freely, but
+// write only through a Node.
+func (f *FSM) Cache() *cache.Cache {
+	return f.cache
+}
+
+// Apply applies one log entry and returns nil or the error it failed with.
+// Lifetimes count from the entry's append time, so replaying the log after
+// a restart yields the same deadlines.
+func (f *FSM) Apply(l *raft.Log) interface{} {
+	var cmd command
+	if err := gob.NewDecoder(bytes.NewReader(l.Data)).Decode(&cmd); err != nil {
+		return fmt.Errorf("decode log entry %d: %w", l.Index, err)
+	}
+
+	switch cmd.Op {
+	case opSet:
+		var value interface{}
+		if err := f.cfg.codec.Unmarshal(cmd.Value, &value); err != nil {
+			return fmt.Errorf("decode value of %q: %w", cmd.Key, err)
+		}
+		d := cmd.Duration
+		if d == 0 {
+			d = f.cache.LifetimeFor(cmd.Key)
+		}
+		item := cache.Item{Value: value, Created: l.AppendedAt}
+		if d > 0 {
+			item.Expired = l.AppendedAt.Add(d).UnixNano()
+			if f.cache.Clock().Now().UnixNano() > item.Expired {
+				f.cache.ClearItems([]string{cmd.Key})
+				return nil
+			}
+		}
+		return f.cache.ImportLocal(map[string]cache.Item{cmd.Key: item}, true)
+	case opDelete:
+		f.cache.ClearItems([]string{cmd.Key})
+		return nil
+	default:
+		return fmt.Errorf("unknown op %d for %q", cmd.Op, cmd.Key)
+	}
+}
+
+// Snapshot captures the cache's entries with Cache.Save.
+func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
+	var buf bytes.Buffer
+	if err := f.cache.Save(&buf); err != nil {
+		return nil, err
+	}
+	return &fsmSnapshot{data: buf.Bytes()}, nil
+}
+
+// Restore replaces the cache's entries with a snapshot taken by Snapshot.
+func (f *FSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+	if err := f.cache.Flush(); err != nil {
+		return err
+	}
+	return f.cache.Load(rc)
+}
+
+type fsmSnapshot struct {
+	data []byte
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if _, err := sink.Write(s.data); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
+
+// Node writes to a replicated cache through Raft.
+type Node struct {
+	raft *raft.Raft
+	fsm  *FSM
+}
+
+// New returns a Node proposing writes to r, which must have been created
+// with fsm.
+func New(r *raft.Raft, fsm *FSM) *Node {
+	return &Node{raft: r, fsm: fsm}
+}
+
+// Set stores value under key on every replica. It returns once the write
+// is committed and applied on this node, or fails when ctx is done first.
+// duration is handled as in cache.Cache.Set.
+func (n *Node) Set(ctx context.Context, key string, value interface{}, duration time.Duration) error {
+	data, err := n.fsm.cfg.codec.Marshal(&value)
+	if err != nil {
+		return err
+	}
+	return n.apply(ctx, command{Op: opSet, Key: key, Value: data, Duration: duration})
+}
+
+// Delete removes key on every replica. Deleting a missing key is not an
+// error.
+func (n *Node) Delete(ctx context.Context, key string) error {
+	return n.apply(ctx, command{Op: opDelete, Key: key})
+}
+
+func (n *Node) apply(ctx context.Context, cmd command) error {
+	timeout, err := timeoutOf(ctx)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cmd); err != nil {
+		return err
+	}
+	f := n.raft.Apply(buf.Bytes(), timeout)
+	if err := f.Error(); err != nil {
+		return err
+	}
+	if err, ok := f.Response().(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Get reads key from this node's replica. On a follower the value may lag
+// behind the leader; use GetConsistent to read the latest write.
+func (n *Node) Get(key string) (interface{}, bool) {
+	return n.fsm.cache.Get(key)
+}
+
+// GetConsistent reads key after every write committed before the call has
+// been applied on this node. It must be called on the leader.
+func (n *Node) GetConsistent(ctx context.Context, key string) (interface{}, bool, error) {
+	timeout, err := timeoutOf(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := n.raft.Barrier(timeout).Error(); err != nil {
+		return nil, false, err
+	}
+	value, ok := n.fsm.cache.Get(key)
+	return value, ok, nil
+}
+
+// timeoutOf converts ctx's deadline to a Raft timeout, 0 meaning none.
+func timeoutOf(ctx context.Context) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, nil
+	}
+	return time.Until(deadline), nil
+}
@@ -0,0 +1,229 @@
+package raftcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"io"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+
+	"github.com/hashicorp/raft"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// entry returns a log entry carrying cmd, appended at appended.
+func entry(t *testing.T, cmd command, appended time.Time) *raft.Log {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cmd); err != nil {
+		t.Fatal(err)
+	}
+	return &raft.Log{Index: 1, Data: buf.Bytes(), AppendedAt: appended}
+}
+
+func encode(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	data, err := cache.GobCodec.Marshal(&value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestFSMApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      command
+		appended time.Time
+		want     interface{}
+		expires  time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "set",
+			cmd:      command{Op: opSet, Key: "k", Value: encode(t, "new"), Duration: time.Minute},
+			appended: epoch.Add(-10 * time.Second),
+			want:     "new",
+			expires:  epoch.Add(50 * time.Second),
+		},
+		{
+			name:     "default lifetime",
+			cmd:      command{Op: opSet, Key: "k", Value: encode(t, "new")},
+			appended: epoch,
+			want:     "new",
+			expires:  epoch.Add(time.Hour),
+		},
+		{
+			name:     "no expiration",
+			cmd:      command{Op: opSet, Key: "k", Value: encode(t, "new"), Duration: cache.NoExpiration},
+			appended: epoch.Add(-1000 * time.Hour),
+			want:     "new",
+		},
+		{
+			name:     "expired before it was applied",
+			cmd:      command{Op: opSet, Key: "k", Value: encode(t, "new"), Duration: time.Minute},
+			appended: epoch.Add(-2 * time.Minute),
+		},
+		{
+			name:     "delete",
+			cmd:      command{Op: opDelete, Key: "k"},
+			appended: epoch,
+		},
+		{
+			name:     "bad value",
+			cmd:      command{Op: opSet, Key: "k", Value: []byte("garbage")},
+			appended: epoch,
+			want:     "old",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown op",
+			cmd:      command{Key: "k"},
+			appended: epoch,
+			want:     "old",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.New(time.Hour, 0, cache.WithClock(cache.NewFakeClock(epoch)))
+			if err := c.Set("k", "old", 0); err != nil {
+				t.Fatal(err)
+			}
+			resp := NewFSM(c).Apply(entry(t, tt.cmd, tt.appended))
+			if err, _ := resp.(error); (err != nil) != tt.wantErr {
+				t.Fatalf("Apply = %v, want error %v", resp, tt.wantErr)
+			}
+
+			item, ok := c.GetItem("k")
+			if tt.want == nil {
+				if ok {
+					t.Errorf("cache holds %v, want nothing", item.Value)
+				}
+				return
+			}
+			if !ok || item.Value != tt.want {
+				t.Fatalf("cache holds %v, %v; want %v", item, ok, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			var want int64
+			if !tt.expires.IsZero() {
+				want = tt.expires.UnixNano()
+			}
+			if item.Expired != want {
+				t.Errorf("expiry %v, want %v", time.Unix(0, item.Expired).UTC(), tt.expires)
+			}
+		})
+	}
+}
+
+// sink is a raft.SnapshotSink writing to a buffer.
+type sink struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (s *sink) ID() string    { return "test" }
+func (s *sink) Cancel() error { return nil }
+func (s *sink) Close() error  { s.closed = true; return nil }
+
+func TestFSMSnapshotRestore(t *testing.T) {
+	src := cache.New(0, 0)
+	if err := src.Set("a", "1", 0); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := NewFSM(src).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s sink
+	if err := snap.Persist(&s); err != nil || !s.closed {
+		t.Fatalf("Persist = %v, closed %v", err, s.closed)
+	}
+
+	dst := cache.New(0, 0)
+	if err := dst.Set("stale", "x", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFSM(dst).Restore(io.NopCloser(&s.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := dst.Get("a"); !ok || v != "1" {
+		t.Errorf("Get(a) = %v, %v after Restore", v, ok)
+	}
+	if _, ok := dst.Get("stale"); ok {
+		t.Error("Restore kept an entry missing from the snapshot")
+	}
+}
+
+// newNode starts a single-node Raft cluster over c and waits until it
+// leads.
+func newNode(t *testing.T, c *cache.Cache) *Node {
+	t.Helper()
+	conf := raft.DefaultConfig()
+	conf.LocalID = "node"
+	conf.HeartbeatTimeout = 50 * time.Millisecond
+	conf.ElectionTimeout = 50 * time.Millisecond
+	conf.LeaderLeaseTimeout = 50 * time.Millisecond
+	conf.CommitTimeout = 5 * time.Millisecond
+	conf.LogOutput = io.Discard
+
+	logs := raft.NewInmemStore()
+	snaps := raft.NewInmemSnapshotStore()
+	addr, transport := raft.NewInmemTransport("")
+	configuration := raft.Configuration{Servers: []raft.Server{{ID: conf.LocalID, Address: addr}}}
+	if err := raft.BootstrapCluster(conf, logs, logs, snaps, transport, configuration); err != nil {
+		t.Fatal(err)
+	}
+	fsm := NewFSM(c)
+	r, err := raft.NewRaft(conf, fsm, logs, logs, snaps, transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Shutdown().Error() })
+
+	select {
+	case <-r.LeaderCh():
+	case <-time.After(5 * time.Second):
+		t.Fatal("node did not become leader")
+	}
+	return New(r, fsm)
+}
+
+func TestNode(t *testing.T) {
+	c := cache.New(0, 0)
+	n := newNode(t, c)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := n.Set(ctx, "flag", true, cache.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := n.Get("flag"); !ok || v != true {
+		t.Errorf("Get = %v, %v; want true", v, ok)
+	}
+	if v, ok, err := n.GetConsistent(ctx, "flag"); err != nil || !ok || v != true {
+		t.Errorf("GetConsistent = %v, %v, %v; want true", v, ok, err)
+	}
+	if err := n.Delete(ctx, "flag"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("flag"); ok {
+		t.Error("Delete was not applied")
+	}
+	if err := n.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete of a missing key = %v", err)
+	}
+
+	done, stop := context.WithCancel(context.Background())
+	stop()
+	if err := n.Set(done, "flag", false, 0); err != context.Canceled {
+		t.Errorf("Set with a done context = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,99 @@
+// Package ratelimit implements per-key rate limiting on top of a cache.
+// Counters are updated atomically with Cache.Update and expire with their
+// window, so a limiter needs no cleanup of its own. A limiter may share a
+// cache with other data; its counters are stored under "ratelimit:" keys.
+package ratelimit
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	cache "go-in-memory-cache"
+)
+
+// Limiter reports whether another event for key fits within limit events
+// per window, and records it if so.
+type Limiter interface {
+	Allow(key string, limit int, window time.Duration) bool
+}
+
+var errLimited = errors.New("rate limited")
+
+// FixedWindow counts events in consecutive windows aligned to the Unix
+// epoch. It is cheap but allows up to twice limit across a window
+// boundary.
+type FixedWindow struct {
+	cache *cache.Cache
+	now   func() time.Time
+}
+
+// NewFixedWindow returns a FixedWindow keeping its counters in c. Windows
+// are measured with c's Clock, as the counters' expiry is.
+func NewFixedWindow(c *cache.Cache) *FixedWindow {
+	return &FixedWindow{cache: c, now: c.Clock().Now}
+}
+
+func (l *FixedWindow) Allow(key string, limit int, window time.Duration) bool {
+	if window <= 0 || limit <= 0 {
+		return false
+	}
+	now := l.now().UnixNano()
+	idx := now / int64(window)
+	ttl := time.Duration((idx+1)*int64(window) - now)
+
+	_, err := l.cache.Update(counterKey("fixed", key, window, idx), func(old interface{}, exists bool) (interface{}, error) {
+		n, _ := old.(int64)
+		if n >= int64(limit) {
+			return nil, errLimited
+		}
+		return n + 1, nil
+	}, ttl)
+	return err == nil
+}
+
+// SlidingWindow approximates a true sliding window by weighting the
+// previous fixed window's count by how much of it still overlaps the last
+// window of time. It smooths out FixedWindow's boundary bursts at the cost
+// of one extra read.
+type SlidingWindow struct {
+	cache *cache.Cache
+	now   func() time.Time
+}
+
+// NewSlidingWindow returns a SlidingWindow keeping its counters in c,
+// measuring windows with c's Clock.
+func NewSlidingWindow(c *cache.Cache) *SlidingWindow {
+	return &SlidingWindow{cache: c, now: c.Clock().Now}
+}
+
+func (l *SlidingWindow) Allow(key string, limit int, window time.Duration) bool {
+	if window <= 0 || limit <= 0 {
+		return false
+	}
+	now := l.now().UnixNano()
+	idx := now / int64(window)
+	elapsed := float64(now-idx*int64(window)) / float64(window)
+
+	var prev int64
+	if v, state := l.cache.Lookup(counterKey("sliding", key, window, idx-1)); state == cache.StateFound {
+		prev, _ = v.(int64)
+	}
+	weighted := float64(prev) * (1 - elapsed)
+
+	// The current window's counter is still needed as the previous one
+	// during the next window.
+	ttl := time.Duration((idx+2)*int64(window) - now)
+	_, err := l.cache.Update(counterKey("sliding", key, window, idx), func(old interface{}, exists bool) (interface{}, error) {
+		n, _ := old.(int64)
+		if weighted+float64(n) >= float64(limit) {
+			return nil, errLimited
+		}
+		return n + 1, nil
+	}, ttl)
+	return err == nil
+}
+
+func counterKey(kind, key string, window time.Duration, idx int64) string {
+	return "ratelimit:" + kind + ":" + key + ":" + strconv.FormatInt(int64(window), 10) + ":" + strconv.FormatInt(idx, 10)
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newCache(t *testing.T) (*cache.Cache, *cache.FakeClock) {
+	t.Helper()
+	clock := cache.NewFakeClock(epoch)
+	c := cache.New(0, 0, cache.WithClock(clock))
+	t.Cleanup(func() { c.Close() })
+	return c, clock
+}
+
+// allowN calls Allow n times and returns how many were allowed.
+func allowN(l Limiter, key string, limit int, window time.Duration, n int) int {
+	allowed := 0
+	for range n {
+		if l.Allow(key, limit, window) {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestFixedWindow(t *testing.T) {
+	c, clock := newCache(t)
+	l := NewFixedWindow(c)
+	clock.Advance(10 * time.Second)
+
+	if got := allowN(l, "k", 3, time.Minute, 5); got != 3 {
+		t.Errorf("allowed %d of 5 events, want 3", got)
+	}
+	if !l.Allow("other", 3, time.Minute) {
+		t.Error("a full key limited another key")
+	}
+	if !l.Allow("k", 3, time.Hour) {
+		t.Error("a full window limited the same key with another window")
+	}
+
+	// The counter lives exactly until its window ends.
+	key := counterKey("fixed", "k", time.Minute, epoch.UnixNano()/int64(time.Minute))
+	if d, ok := c.TTL(key); !ok || d != 50*time.Second {
+		t.Errorf("counter TTL = %v, %v; want 50s, true", d, ok)
+	}
+	clock.Advance(49 * time.Second)
+	if l.Allow("k", 3, time.Minute) {
+		t.Error("allowed an event before the window ended")
+	}
+	clock.Advance(time.Second)
+	if got := allowN(l, "k", 3, time.Minute, 5); got != 3 {
+		t.Errorf("allowed %d of 5 events in the next window, want 3", got)
+	}
+	clock.Advance(time.Nanosecond)
+	if _, ok := c.TTL(key); ok {
+		t.Error("counter outlived its window")
+	}
+}
+
+func TestFixedWindowBoundaryBurst(t *testing.T) {
+	c, clock := newCache(t)
+	l := NewFixedWindow(c)
+	clock.Advance(time.Minute - time.Second)
+	got := allowN(l, "k", 3, time.Minute, 3)
+	clock.Advance(time.Second)
+	got += allowN(l, "k", 3, time.Minute, 3)
+	if got != 6 {
+		t.Errorf("allowed %d events across the boundary, want 6", got)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	c, clock := newCache(t)
+	l := NewSlidingWindow(c)
+
+	if got := allowN(l, "k", 10, time.Minute, 12); got != 10 {
+		t.Errorf("allowed %d of 12 events, want 10", got)
+	}
+	// The counter is kept through the next window, where it is weighted.
+	idx := epoch.UnixNano() / int64(time.Minute)
+	prev := counterKey("sliding", "k", time.Minute, idx)
+	if d, ok := c.TTL(prev); !ok || d != 2*time.Minute {
+		t.Errorf("counter TTL = %v, %v; want 2m, true", d, ok)
+	}
+
+	// Halfway through the next window the previous 10 count as 5.
+	clock.Advance(90 * time.Second)
+	if got := allowN(l, "k", 10, time.Minute, 10); got != 5 {
+		t.Errorf("allowed %d of 10 events halfway through, want 5", got)
+	}
+	cur := counterKey("sliding", "k", time.Minute, idx+1)
+	if d, ok := c.TTL(cur); !ok || d != 90*time.Second {
+		t.Errorf("counter TTL = %v, %v; want 1m30s, true", d, ok)
+	}
+
+	// At the start of the third window the 5 events of the second count in
+	// full.
+	clock.Advance(30 * time.Second)
+	if got := allowN(l, "k", 10, time.Minute, 10); got != 5 {
+		t.Errorf("allowed %d of 10 events in the third window, want 5", got)
+	}
+	clock.Advance(time.Nanosecond)
+	if _, ok := c.TTL(prev); ok {
+		t.Error("counter outlived the window after its own")
+	}
+}
+
+func TestInvalidLimits(t *testing.T) {
+	c, _ := newCache(t)
+	for _, l := range []Limiter{NewFixedWindow(c), NewSlidingWindow(c)} {
+		if l.Allow("k", 0, time.Minute) {
+			t.Errorf("%T allowed an event with a zero limit", l)
+		}
+		if l.Allow("k", 1, 0) {
+			t.Errorf("%T allowed an event with a zero window", l)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package redisbus implements cache.Broadcaster over Redis pub/sub.
+// Invalidations are published as JSON on a single channel.
+package redisbus
+
+import (
+	"context"
+	"encoding/json"
+
+	cache "go-in-memory-cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Broadcaster struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// New returns a Broadcaster publishing to and subscribing on channel.
+func New(client redis.UniversalClient, channel string) *Broadcaster {
+	return &Broadcaster{client: client, channel: channel}
+}
+
+func (b *Broadcaster) Publish(ctx context.Context, msg cache.Invalidation) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Subscribe blocks until ctx is done. The client reconnects on its own, so
+// invalidations published while it is disconnected are lost. Messages that
+// are not valid invalidations are ignored.
+func (b *Broadcaster) Subscribe(ctx context.Context, fn func(cache.Invalidation)) error {
+	sub := b.client.Subscribe(ctx, b.channel)
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+	ch := sub.Channel()
+	for {
+		select {
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var msg cache.Invalidation
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				continue
+			}
+			fn(msg)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
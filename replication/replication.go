@@ -0,0 +1,171 @@
+// Package replication keeps the caches of several service instances
+// roughly in sync by forwarding each instance's writes to its peers over
+// gRPC. The service is defined in replicationpb/replication.proto.
+//
+// Every instance installs a Peer, or Peers for several, as its cache's
+// Store and serves the cache with Register:
+//
+//	peers := replication.Peers{replication.NewPeer(conn)}
+//	c := cache.New(time.Minute, time.Minute,
+//		cache.WithWriteBehind(peers, cache.WriteBehindConfig{}))
+//	replication.Register(srv, c)
+//
+// With WithWriteBehind, writes are propagated asynchronously, coalesced per
+// key and retried on failure, so each peer sees at least the latest write
+// to a key. Mutations received from peers are applied without being
+// forwarded again. Expiry deadlines travel as absolute times, so peers'
+// clocks should agree.
+package replication
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/replication/replicationpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc -I replicationpb --go_out=replicationpb --go_opt=paths=source_relative --go-grpc_out=replicationpb --go-grpc_opt=paths=source_relative replicationpb/replication.proto
+
+type config struct {
+	codec cache.Codec
+}
+
+type Option func(*config)
+
+// WithCodec selects how values are encoded on the wire. Both sides must use
+// the same codec. It defaults to cache.GobCodec, which requires custom
+// value types to be registered with gob.Register.
+func WithCodec(codec cache.Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{codec: cache.GobCodec}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Server applies mutations forwarded by peers to a cache.
+type Server struct {
+	replicationpb.UnimplementedReplicationServer
+	cache *cache.Cache
+	cfg   config
+}
+
+func New(c *cache.Cache, opts ...Option) *Server {
+	return &Server{cache: c, cfg: newConfig(opts)}
+}
+
+// Register serves c on s.
+func Register(s grpc.ServiceRegistrar, c *cache.Cache, opts ...Option) {
+	replicationpb.RegisterReplicationServer(s, New(c, opts...))
+}
+
+// Apply stores or removes each mutation's key. It bypasses the cache's
+// Store, so applied mutations are not forwarded again.
+func (s *Server) Apply(ctx context.Context, req *replicationpb.ApplyRequest) (*replicationpb.ApplyResponse, error) {
+	now := s.cache.Clock().Now()
+	for _, m := range req.Mutations {
+		switch m.Op {
+		case replicationpb.Mutation_OP_SET:
+			if m.ExpiresUnixNano > 0 && now.UnixNano() > m.ExpiresUnixNano {
+				s.cache.ClearItems([]string{m.Key})
+				continue
+			}
+			var value interface{}
+			if err := s.cfg.codec.Unmarshal(m.Value, &value); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "decode value of %q: %v", m.Key, err)
+			}
+			item := cache.Item{Value: value, Created: now, Expired: m.ExpiresUnixNano}
+			if err := s.cache.ImportLocal(map[string]cache.Item{m.Key: item}, true); err != nil {
+				return nil, status.Error(codes.Unavailable, err.Error())
+			}
+		case replicationpb.Mutation_OP_DELETE:
+			s.cache.ClearItems([]string{m.Key})
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown op %v for %q", m.Op, m.Key)
+		}
+	}
+	return &replicationpb.ApplyResponse{}, nil
+}
+
+// Peer forwards writes to one remote instance. It implements
+// cache.ExpiringStore.
+type Peer struct {
+	client replicationpb.ReplicationClient
+	cfg    config
+}
+
+// NewPeer returns a Peer sending to the instance behind conn.
+func NewPeer(conn grpc.ClientConnInterface, opts ...Option) *Peer {
+	return &Peer{client: replicationpb.NewReplicationClient(conn), cfg: newConfig(opts)}
+}
+
+// Put forwards value without expiry.
+func (p *Peer) Put(ctx context.Context, key string, value interface{}) error {
+	return p.PutExpiring(ctx, key, value, time.Time{})
+}
+
+// PutExpiring forwards value to expire at expires, or never if it is zero.
+func (p *Peer) PutExpiring(ctx context.Context, key string, value interface{}, expires time.Time) error {
+	data, err := p.cfg.codec.Marshal(&value)
+	if err != nil {
+		return err
+	}
+	m := &replicationpb.Mutation{Op: replicationpb.Mutation_OP_SET, Key: key, Value: data}
+	if !expires.IsZero() {
+		m.ExpiresUnixNano = expires.UnixNano()
+	}
+	return p.apply(ctx, m)
+}
+
+func (p *Peer) Delete(ctx context.Context, key string) error {
+	return p.apply(ctx, &replicationpb.Mutation{Op: replicationpb.Mutation_OP_DELETE, Key: key})
+}
+
+func (p *Peer) apply(ctx context.Context, m *replicationpb.Mutation) error {
+	_, err := p.client.Apply(ctx, &replicationpb.ApplyRequest{Mutations: []*replicationpb.Mutation{m}})
+	return err
+}
+
+// Peers forwards every write to all of its peers concurrently. A write
+// fails if any peer fails, so a retry resends it to all of them; peers
+// apply repeated writes idempotently.
+type Peers []*Peer
+
+func (ps Peers) Put(ctx context.Context, key string, value interface{}) error {
+	return ps.each(func(p *Peer) error { return p.Put(ctx, key, value) })
+}
+
+func (ps Peers) PutExpiring(ctx context.Context, key string, value interface{}, expires time.Time) error {
+	return ps.each(func(p *Peer) error { return p.PutExpiring(ctx, key, value, expires) })
+}
+
+func (ps Peers) Delete(ctx context.Context, key string) error {
+	return ps.each(func(p *Peer) error { return p.Delete(ctx, key) })
+}
+
+func (ps Peers) each(fn func(*Peer) error) error {
+	errs := make([]error, len(ps))
+	var wg sync.WaitGroup
+	for i, p := range ps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(p)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
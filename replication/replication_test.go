@@ -0,0 +1,161 @@
+package replication
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/replication/replicationpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// serve starts a replication server for c and returns a connection to it.
+func serve(t *testing.T, c *cache.Cache) *grpc.ClientConn {
+	t.Helper()
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	Register(srv, c)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// countingStore counts the writes it forwards to next.
+type countingStore struct {
+	next   cache.Store
+	writes atomic.Int32
+}
+
+func (s *countingStore) Put(ctx context.Context, key string, value interface{}) error {
+	s.writes.Add(1)
+	return s.next.Put(ctx, key, value)
+}
+
+func (s *countingStore) Delete(ctx context.Context, key string) error {
+	s.writes.Add(1)
+	return s.next.Delete(ctx, key)
+}
+
+func TestReplication(t *testing.T) {
+	clock := cache.NewFakeClock(epoch)
+	// b forwards its own writes back to a, so a loop would show up as
+	// writes through b's store.
+	back := &countingStore{}
+	b := cache.New(0, 0, cache.WithClock(clock), cache.WithWriteThrough(back))
+	a := cache.New(0, 0, cache.WithClock(clock), cache.WithWriteThrough(Peers{NewPeer(serve(t, b))}))
+	back.next = NewPeer(serve(t, a))
+
+	if err := a.Set("k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	item, ok := b.GetItem("k")
+	if !ok || item.Value != "v" {
+		t.Fatalf("peer holds %v, %v; want v", item, ok)
+	}
+	if want := epoch.Add(time.Minute).UnixNano(); item.Expired != want {
+		t.Errorf("peer expiry %v, want %v", time.Unix(0, item.Expired).UTC(), time.Unix(0, want).UTC())
+	}
+	if err := a.Set("forever", "v", cache.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := b.TTL("forever"); !ok || d != cache.NoExpiration {
+		t.Errorf("peer TTL = %v, %v; want NoExpiration", d, ok)
+	}
+
+	if err := a.Delete("k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.Get("k"); ok {
+		t.Error("delete did not reach the peer")
+	}
+	if n := back.writes.Load(); n != 0 {
+		t.Errorf("peer forwarded %d applied mutations", n)
+	}
+}
+
+func TestApply(t *testing.T) {
+	var v interface{} = "new"
+	value, err := cache.GobCodec.Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		mutation *replicationpb.Mutation
+		code     codes.Code
+		want     interface{}
+	}{
+		{
+			name:     "set",
+			mutation: &replicationpb.Mutation{Op: replicationpb.Mutation_OP_SET, Key: "k", Value: value},
+			want:     "new",
+		},
+		{
+			name:     "set already expired",
+			mutation: &replicationpb.Mutation{Op: replicationpb.Mutation_OP_SET, Key: "k", Value: value, ExpiresUnixNano: epoch.Add(-time.Second).UnixNano()},
+		},
+		{
+			name:     "delete",
+			mutation: &replicationpb.Mutation{Op: replicationpb.Mutation_OP_DELETE, Key: "k"},
+		},
+		{
+			name:     "bad value",
+			mutation: &replicationpb.Mutation{Op: replicationpb.Mutation_OP_SET, Key: "k", Value: []byte("garbage")},
+			code:     codes.InvalidArgument,
+			want:     "old",
+		},
+		{
+			name:     "unknown op",
+			mutation: &replicationpb.Mutation{Key: "k"},
+			code:     codes.InvalidArgument,
+			want:     "old",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.New(0, 0, cache.WithClock(cache.NewFakeClock(epoch)))
+			if err := c.Set("k", "old", 0); err != nil {
+				t.Fatal(err)
+			}
+			_, err := New(c).Apply(context.Background(), &replicationpb.ApplyRequest{Mutations: []*replicationpb.Mutation{tt.mutation}})
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("Apply = %v, want code %v", err, tt.code)
+			}
+			if got, _ := c.Get("k"); got != tt.want {
+				t.Errorf("cache holds %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeersFailIfAnyFails(t *testing.T) {
+	up := cache.New(0, 0)
+	frozen := cache.New(0, 0)
+	frozen.Freeze()
+	peers := Peers{NewPeer(serve(t, up)), NewPeer(serve(t, frozen))}
+
+	if err := peers.Put(context.Background(), "k", "v"); err == nil {
+		t.Error("Put succeeded though a peer failed")
+	}
+	if _, ok := up.Get("k"); !ok {
+		t.Error("healthy peer did not receive the write")
+	}
+}
@@ -0,0 +1,354 @@
+package resp
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	cache "go-in-memory-cache"
+)
+
+var (
+	errNotInteger = errors.New("ERR value is not an integer or out of range")
+	errOverflow   = errors.New("ERR increment or decrement would overflow")
+	errWrongType  = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errSyntax     = errors.New("ERR syntax error")
+)
+
+// exec runs one command and writes its reply. It reports whether the
+// connection should be closed afterwards.
+func (s *Server) exec(w writer, args [][]byte) bool {
+	name := strings.ToUpper(string(args[0]))
+	args = args[1:]
+
+	arity := func(lo, hi int) bool {
+		if len(args) < lo || (hi >= 0 && len(args) > hi) {
+			w.error("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+			return false
+		}
+		return true
+	}
+
+	switch name {
+	case "PING":
+		if !arity(0, 1) {
+			break
+		}
+		if len(args) == 1 {
+			w.bulk(args[0])
+		} else {
+			w.simple("PONG")
+		}
+	case "ECHO":
+		if arity(1, 1) {
+			w.bulk(args[0])
+		}
+	case "QUIT":
+		w.simple("OK")
+		return true
+	case "COMMAND":
+		w.array(0)
+	case "GET":
+		if arity(1, 1) {
+			s.get(w, string(args[0]))
+		}
+	case "SET":
+		if arity(2, -1) {
+			s.set(w, args)
+		}
+	case "DEL":
+		if !arity(1, -1) {
+			break
+		}
+		var n int64
+		for _, ok := range s.cache.MDelete(keys(args)...) {
+			if ok {
+				n++
+			}
+		}
+		w.integer(n)
+	case "EXISTS":
+		if !arity(1, -1) {
+			break
+		}
+		var n int64
+		for _, key := range args {
+			if _, ok := s.cache.TTL(string(key)); ok {
+				n++
+			}
+		}
+		w.integer(n)
+	case "EXPIRE", "PEXPIRE":
+		if !arity(2, 2) {
+			break
+		}
+		unit := time.Second
+		if name == "PEXPIRE" {
+			unit = time.Millisecond
+		}
+		s.expire(w, string(args[0]), args[1], unit)
+	case "TTL", "PTTL":
+		if !arity(1, 1) {
+			break
+		}
+		unit := time.Second
+		if name == "PTTL" {
+			unit = time.Millisecond
+		}
+		s.ttl(w, string(args[0]), unit)
+	case "INCR", "DECR":
+		if !arity(1, 1) {
+			break
+		}
+		delta := int64(1)
+		if name == "DECR" {
+			delta = -1
+		}
+		s.incr(w, string(args[0]), delta)
+	case "INCRBY", "DECRBY":
+		if !arity(2, 2) {
+			break
+		}
+		delta, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil || (name == "DECRBY" && delta == math.MinInt64) {
+			w.error(errNotInteger.Error())
+			break
+		}
+		if name == "DECRBY" {
+			delta = -delta
+		}
+		s.incr(w, string(args[0]), delta)
+	case "KEYS":
+		if !arity(1, 1) {
+			break
+		}
+		keys := s.cache.KeysMatching(string(args[0]))
+		w.array(len(keys))
+		for _, key := range keys {
+			w.bulk([]byte(key))
+		}
+	case "DBSIZE":
+		if arity(0, 0) {
+			w.integer(int64(s.cache.Count()))
+		}
+	case "FLUSHDB", "FLUSHALL":
+		if err := s.cache.Flush(); err != nil {
+			w.error("ERR " + err.Error())
+			break
+		}
+		w.simple("OK")
+	default:
+		w.error("ERR unknown command '" + strings.ToLower(name) + "'")
+	}
+	return false
+}
+
+func keys(args [][]byte) []string {
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = string(arg)
+	}
+	return keys
+}
+
+func (s *Server) get(w writer, key string) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		w.null()
+		return
+	}
+	b, ok := format(value)
+	if !ok {
+		w.error(errWrongType.Error())
+		return
+	}
+	w.bulk(b)
+}
+
+// format renders values stored from Go code the way Redis would return
+// them. Anything that is not a string, byte slice or number is reported as
+// the wrong type.
+func format(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), true
+	case []byte:
+		return v, true
+	case int:
+		return strconv.AppendInt(nil, int64(v), 10), true
+	case int8:
+		return strconv.AppendInt(nil, int64(v), 10), true
+	case int16:
+		return strconv.AppendInt(nil, int64(v), 10), true
+	case int32:
+		return strconv.AppendInt(nil, int64(v), 10), true
+	case int64:
+		return strconv.AppendInt(nil, v, 10), true
+	case uint:
+		return strconv.AppendUint(nil, uint64(v), 10), true
+	case uint8:
+		return strconv.AppendUint(nil, uint64(v), 10), true
+	case uint16:
+		return strconv.AppendUint(nil, uint64(v), 10), true
+	case uint32:
+		return strconv.AppendUint(nil, uint64(v), 10), true
+	case uint64:
+		return strconv.AppendUint(nil, v, 10), true
+	case float32:
+		return strconv.AppendFloat(nil, float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), true
+	}
+	return nil, false
+}
+
+func (s *Server) set(w writer, args [][]byte) {
+	key, value := string(args[0]), string(args[1])
+	var duration time.Duration
+	var nx, xx bool
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if duration != 0 || i+1 == len(args) {
+				w.error(errSyntax.Error())
+				return
+			}
+			n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+			if err != nil || n <= 0 {
+				w.error("ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Second
+			if strings.EqualFold(string(args[i]), "PX") {
+				unit = time.Millisecond
+			}
+			duration = time.Duration(n) * unit
+			i++
+		default:
+			w.error(errSyntax.Error())
+			return
+		}
+	}
+	if nx && xx {
+		w.error(errSyntax.Error())
+		return
+	}
+
+	var err error
+	switch {
+	case nx:
+		err = s.cache.Add(key, value, duration)
+	case xx:
+		err = s.cache.Replace(key, value, duration)
+	default:
+		err = s.cache.Set(key, value, duration)
+	}
+	switch {
+	case errors.Is(err, cache.ErrKeyExists), errors.Is(err, cache.ErrKeyNotFound):
+		w.null()
+	case err != nil:
+		w.error("ERR " + err.Error())
+	default:
+		w.simple("OK")
+	}
+}
+
+func (s *Server) expire(w writer, key string, arg []byte, unit time.Duration) {
+	n, err := strconv.ParseInt(string(arg), 10, 64)
+	if err != nil {
+		w.error(errNotInteger.Error())
+		return
+	}
+	if n <= 0 {
+		if _, ok := s.cache.Pop(key); ok {
+			w.integer(1)
+		} else {
+			w.integer(0)
+		}
+		return
+	}
+	if err := s.cache.Touch(key, time.Duration(n)*unit); err != nil {
+		w.integer(0)
+		return
+	}
+	w.integer(1)
+}
+
+func (s *Server) ttl(w writer, key string, unit time.Duration) {
+	d, ok := s.cache.TTL(key)
+	switch {
+	case !ok:
+		w.integer(-2)
+	case d == cache.NoExpiration:
+		w.integer(-1)
+	default:
+		w.integer(int64((d + unit/2) / unit))
+	}
+}
+
+func (s *Server) incr(w writer, key string, delta int64) {
+	value, err := s.cache.Update(key, func(old interface{}, exists bool) (interface{}, error) {
+		if !exists {
+			return strconv.FormatInt(delta, 10), nil
+		}
+		var n int64
+		switch v := old.(type) {
+		case int:
+			if !addOK(int64(v), delta) {
+				return nil, errOverflow
+			}
+			return v + int(delta), nil
+		case int64:
+			if !addOK(v, delta) {
+				return nil, errOverflow
+			}
+			return v + delta, nil
+		case string:
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, errNotInteger
+			}
+			n = parsed
+		case []byte:
+			parsed, err := strconv.ParseInt(string(v), 10, 64)
+			if err != nil {
+				return nil, errNotInteger
+			}
+			n = parsed
+		default:
+			return nil, errNotInteger
+		}
+		if !addOK(n, delta) {
+			return nil, errOverflow
+		}
+		return strconv.FormatInt(n+delta, 10), nil
+	}, cache.KeepTTL)
+	if err != nil {
+		if errors.Is(err, errNotInteger) || errors.Is(err, errOverflow) {
+			w.error(err.Error())
+		} else {
+			w.error("ERR " + err.Error())
+		}
+		return
+	}
+
+	switch v := value.(type) {
+	case int:
+		w.integer(int64(v))
+	case int64:
+		w.integer(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		w.integer(n)
+	}
+}
+
+func addOK(a, b int64) bool {
+	return !(b > 0 && a > math.MaxInt64-b) && !(b < 0 && a < math.MinInt64-b)
+}
@@ -0,0 +1,55 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr error
+	}{
+		{name: "array", input: "*2\r\n$3\r\nGET\r\n$1\r\nk\r\n", want: []string{"GET", "k"}},
+		{name: "empty bulk", input: "*2\r\n$4\r\nECHO\r\n$0\r\n\r\n", want: []string{"ECHO", ""}},
+		{name: "inline", input: "SET  k   v\r\n", want: []string{"SET", "k", "v"}},
+		{name: "inline with bare newline", input: "PING\n", want: []string{"PING"}},
+		{name: "blank line", input: "\r\n"},
+		{name: "bad array length", input: "*x\r\n", wantErr: errProtocol},
+		{name: "missing bulk header", input: "*1\r\n:1\r\n", wantErr: errProtocol},
+		{name: "negative bulk length", input: "*1\r\n$-1\r\n", wantErr: errProtocol},
+		{name: "oversized bulk", input: "*1\r\n$536870913\r\n", wantErr: errProtocol},
+		{name: "bulk without CRLF", input: "*1\r\n$2\r\nabcd\r\n", wantErr: errProtocol},
+		{name: "truncated bulk", input: "*1\r\n$5\r\nab", wantErr: io.ErrUnexpectedEOF},
+		{name: "eof", input: "", wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := reader{bufio.NewReader(strings.NewReader(tt.input))}
+			args, err := r.readCommand()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readCommand = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, arg := range args {
+				got = append(got, string(arg))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readCommand = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCommandLineTooLong(t *testing.T) {
+	r := reader{bufio.NewReaderSize(strings.NewReader(strings.Repeat("x", 64)+"\r\n"), 16)}
+	if _, err := r.readCommand(); !errors.Is(err, errProtocol) {
+		t.Errorf("readCommand = %v, want %v", err, errProtocol)
+	}
+}
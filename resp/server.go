@@ -0,0 +1,79 @@
+// Package resp serves a cache over a subset of the Redis protocol so that
+// redis-cli and existing Redis clients can talk to an embedded cache during
+// development.
+//
+// Supported commands are PING, ECHO, GET, SET (with EX, PX, NX and XX), DEL,
+// EXISTS, EXPIRE, PEXPIRE, TTL, PTTL, INCR, INCRBY, DECR, DECRBY, KEYS,
+// DBSIZE, FLUSHDB, FLUSHALL and QUIT. SET without EX or PX uses the cache's
+// default lifetime.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/internal/netserver"
+)
+
+var ErrServerClosed = errors.New("resp: server closed")
+
+type Server struct {
+	cache *cache.Cache
+	srv   *netserver.Server
+}
+
+func NewServer(c *cache.Cache) *Server {
+	s := &Server{cache: c}
+	s.srv = netserver.New(s.serveConn, ErrServerClosed)
+	return s
+}
+
+// ListenAndServe listens on the TCP address addr and serves requests until
+// the server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	return s.srv.ListenAndServe(addr)
+}
+
+// Serve accepts connections on l until it fails or the server is closed,
+// in which case it returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	return s.srv.Serve(l)
+}
+
+// Close stops all listeners, closes open connections and waits for their
+// handlers to return. It does not close the cache.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
+
+// serveConn serves conn until the client quits or the connection fails.
+func (s *Server) serveConn(conn net.Conn) {
+	r := reader{bufio.NewReader(conn)}
+	w := writer{bufio.NewWriter(conn)}
+	for {
+		args, err := r.readCommand()
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				w.error("ERR Protocol error")
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := s.exec(w, args)
+		// Flush only once the client has no pipelined requests left.
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package resp
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// step sends a request and expects exactly want in reply, nothing if want
+// is empty. A step with act runs it against the cache and clock instead.
+type step struct {
+	send string
+	want string
+	act  func(c *cache.Cache, clock *cache.FakeClock)
+}
+
+// serve starts a server for c and returns a connection to it.
+func serve(t *testing.T, c *cache.Cache) net.Conn {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(c)
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(l) }()
+	t.Cleanup(func() {
+		s.Close()
+		if err := <-done; !errors.Is(err, ErrServerClosed) {
+			t.Errorf("Serve = %v, want %v", err, ErrServerClosed)
+		}
+	})
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "ping and echo", steps: []step{
+			{send: "PING\r\n", want: "+PONG\r\n"},
+			{send: "*2\r\n$4\r\nPING\r\n$2\r\nhi\r\n", want: "$2\r\nhi\r\n"},
+			{send: "*2\r\n$4\r\necho\r\n$5\r\nhello\r\n", want: "$5\r\nhello\r\n"},
+		}},
+		{name: "set and get", steps: []step{
+			{send: "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$5\r\nhello\r\n", want: "+OK\r\n"},
+			{send: "*2\r\n$3\r\nGET\r\n$1\r\nk\r\n", want: "$5\r\nhello\r\n"},
+			{send: "GET missing\r\n", want: "$-1\r\n"},
+		}},
+		{name: "binary value", steps: []step{
+			{send: "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$4\r\na\r\nb\r\n", want: "+OK\r\n"},
+			{send: "GET k\r\n", want: "$4\r\na\r\nb\r\n"},
+		}},
+		{name: "values stored from Go", steps: []step{
+			{act: func(c *cache.Cache, _ *cache.FakeClock) {
+				c.Set("n", 42, 0)
+				c.Set("f", 1.5, 0)
+				c.Set("s", struct{}{}, 0)
+			}},
+			{send: "GET n\r\n", want: "$2\r\n42\r\n"},
+			{send: "GET f\r\n", want: "$3\r\n1.5\r\n"},
+			{send: "GET s\r\n", want: "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		}},
+		{name: "set nx and xx", steps: []step{
+			{send: "SET k a XX\r\n", want: "$-1\r\n"},
+			{send: "SET k a NX\r\n", want: "+OK\r\n"},
+			{send: "SET k b NX\r\n", want: "$-1\r\n"},
+			{send: "SET k c XX\r\n", want: "+OK\r\n"},
+			{send: "GET k\r\n", want: "$1\r\nc\r\n"},
+			{send: "SET k d NX XX\r\n", want: "-ERR syntax error\r\n"},
+		}},
+		{name: "set ex", steps: []step{
+			{send: "SET k a EX 10\r\n", want: "+OK\r\n"},
+			{send: "TTL k\r\n", want: ":10\r\n"},
+			{send: "PTTL k\r\n", want: ":10000\r\n"},
+			{act: func(_ *cache.Cache, clock *cache.FakeClock) { clock.Advance(11 * time.Second) }},
+			{send: "GET k\r\n", want: "$-1\r\n"},
+			{send: "TTL k\r\n", want: ":-2\r\n"},
+		}},
+		{name: "set px", steps: []step{
+			{send: "SET k a PX 1500\r\n", want: "+OK\r\n"},
+			{send: "TTL k\r\n", want: ":2\r\n"},
+			{act: func(_ *cache.Cache, clock *cache.FakeClock) { clock.Advance(1501 * time.Millisecond) }},
+			{send: "GET k\r\n", want: "$-1\r\n"},
+		}},
+		{name: "set bad expiry", steps: []step{
+			{send: "SET k a EX 0\r\n", want: "-ERR invalid expire time in 'set' command\r\n"},
+			{send: "SET k a EX\r\n", want: "-ERR syntax error\r\n"},
+			{send: "SET k a EX 1 PX 1\r\n", want: "-ERR syntax error\r\n"},
+			{send: "SET k a BOGUS\r\n", want: "-ERR syntax error\r\n"},
+		}},
+		{name: "default lifetime", steps: []step{
+			{send: "SET k a\r\n", want: "+OK\r\n"},
+			{send: "TTL k\r\n", want: ":3600\r\n"},
+		}},
+		{name: "no expiry", steps: []step{
+			{act: func(c *cache.Cache, _ *cache.FakeClock) { c.Set("k", "a", cache.NoExpiration) }},
+			{send: "TTL k\r\n", want: ":-1\r\n"},
+		}},
+		{name: "expire", steps: []step{
+			{send: "EXPIRE k 10\r\n", want: ":0\r\n"},
+			{send: "SET k a\r\n", want: "+OK\r\n"},
+			{send: "EXPIRE k 10\r\n", want: ":1\r\n"},
+			{send: "TTL k\r\n", want: ":10\r\n"},
+			{send: "PEXPIRE k 500\r\n", want: ":1\r\n"},
+			{send: "PTTL k\r\n", want: ":500\r\n"},
+			{send: "EXPIRE k x\r\n", want: "-ERR value is not an integer or out of range\r\n"},
+			{send: "EXPIRE k 0\r\n", want: ":1\r\n"},
+			{send: "EXISTS k\r\n", want: ":0\r\n"},
+		}},
+		{name: "del and exists", steps: []step{
+			{send: "SET a 1\r\n", want: "+OK\r\n"},
+			{send: "SET b 2\r\n", want: "+OK\r\n"},
+			{send: "EXISTS a b c a\r\n", want: ":3\r\n"},
+			{send: "DEL a c\r\n", want: ":1\r\n"},
+			{send: "DBSIZE\r\n", want: ":1\r\n"},
+		}},
+		{name: "incr and decr", steps: []step{
+			{send: "INCR n\r\n", want: ":1\r\n"},
+			{send: "INCRBY n 10\r\n", want: ":11\r\n"},
+			{send: "DECR n\r\n", want: ":10\r\n"},
+			{send: "DECRBY n 15\r\n", want: ":-5\r\n"},
+			{send: "GET n\r\n", want: "$2\r\n-5\r\n"},
+			{send: "INCRBY n x\r\n", want: "-ERR value is not an integer or out of range\r\n"},
+			{send: "DECRBY n -9223372036854775808\r\n", want: "-ERR value is not an integer or out of range\r\n"},
+		}},
+		{name: "incr keeps ttl", steps: []step{
+			{send: "SET n 1 EX 10\r\n", want: "+OK\r\n"},
+			{send: "INCR n\r\n", want: ":2\r\n"},
+			{send: "TTL n\r\n", want: ":10\r\n"},
+		}},
+		{name: "incr errors", steps: []step{
+			{send: "SET s x\r\n", want: "+OK\r\n"},
+			{send: "INCR s\r\n", want: "-ERR value is not an integer or out of range\r\n"},
+			{send: "SET n 9223372036854775807\r\n", want: "+OK\r\n"},
+			{send: "INCR n\r\n", want: "-ERR increment or decrement would overflow\r\n"},
+			{act: func(c *cache.Cache, _ *cache.FakeClock) { c.Set("i", 41, 0) }},
+			{send: "INCR i\r\n", want: ":42\r\n"},
+		}},
+		{name: "keys", steps: []step{
+			{send: "SET user:1 a\r\n", want: "+OK\r\n"},
+			{send: "SET other b\r\n", want: "+OK\r\n"},
+			{send: "KEYS user:*\r\n", want: "*1\r\n$6\r\nuser:1\r\n"},
+		}},
+		{name: "flushdb", steps: []step{
+			{send: "SET k a\r\n", want: "+OK\r\n"},
+			{send: "FLUSHDB\r\n", want: "+OK\r\n"},
+			{send: "DBSIZE\r\n", want: ":0\r\n"},
+		}},
+		{name: "frozen", steps: []step{
+			{act: func(c *cache.Cache, _ *cache.FakeClock) { c.Freeze() }},
+			{send: "SET k a\r\n", want: "-ERR cache is frozen\r\n"},
+			{send: "FLUSHALL\r\n", want: "-ERR cache is frozen\r\n"},
+		}},
+		{name: "pipelined", steps: []step{
+			{send: "SET k a\r\nGET k\r\nINCR n\r\n", want: "+OK\r\n$1\r\na\r\n:1\r\n"},
+		}},
+		{name: "arity and unknown commands", steps: []step{
+			{send: "GET\r\n", want: "-ERR wrong number of arguments for 'get' command\r\n"},
+			{send: "BOGUS\r\n", want: "-ERR unknown command 'bogus'\r\n"},
+			{send: "\r\n"},
+			{send: "PING\r\n", want: "+PONG\r\n"},
+		}},
+		{name: "quit", steps: []step{
+			{send: "QUIT\r\n", want: "+OK\r\n"},
+		}},
+		{name: "protocol error", steps: []step{
+			{send: "*1\r\n+PING\r\n", want: "-ERR Protocol error\r\n"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := cache.NewFakeClock(epoch)
+			c := cache.New(time.Hour, 0, cache.WithClock(clock))
+			defer c.Close()
+			conn := serve(t, c)
+
+			for _, st := range tt.steps {
+				if st.act != nil {
+					st.act(c, clock)
+					continue
+				}
+				if _, err := io.WriteString(conn, st.send); err != nil {
+					t.Fatal(err)
+				}
+				if st.want == "" {
+					continue
+				}
+				conn.SetReadDeadline(time.Now().Add(time.Second))
+				got := make([]byte, len(st.want))
+				if _, err := io.ReadFull(conn, got); err != nil {
+					t.Fatalf("%q: %v (read %q)", st.send, err, got)
+				}
+				if string(got) != st.want {
+					t.Fatalf("%q: got %q, want %q", st.send, got, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerClosesAfterQuit(t *testing.T) {
+	conn := serve(t, cache.New(0, 0))
+	if _, err := io.WriteString(conn, "QUIT\r\nPING\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	got, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "+OK\r\n" {
+		t.Errorf("read %q before the connection closed, want +OK only", got)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	s := NewServer(cache.New(0, 0))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); !errors.Is(err, ErrServerClosed) {
+		t.Errorf("second Close = %v, want %v", err, ErrServerClosed)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Serve(l); !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Serve after Close = %v, want %v", err, ErrServerClosed)
+	}
+}
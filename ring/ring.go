@@ -0,0 +1,221 @@
+// Package ring spreads keys across several cache servers, each serving the
+// gRPC API of package grpcserver, by consistent hashing with virtual
+// nodes. Adding or removing a server only moves the keys it gains or
+// loses.
+package ring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/grpcserver/cachepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultReplicas = 100
+
+// ErrNoNodes is returned by Ring operations while the ring is empty.
+var ErrNoNodes = errors.New("ring has no nodes")
+
+// Hash maps keys to node names. Every node is placed at replicas points on
+// a circle of hash values, and a key belongs to the first node point at or
+// after the key's hash. The zero value is not usable; create one with
+// NewHash. It is not safe for concurrent use.
+type Hash struct {
+	replicas int
+	points   []uint32
+	owners   map[uint32]string
+}
+
+// NewHash returns an empty Hash placing each node at replicas points, 100
+// if replicas <= 0.
+func NewHash(replicas int) *Hash {
+	if replicas <= 0 {
+		replicas = defaultReplicas
+	}
+	return &Hash{replicas: replicas, owners: make(map[uint32]string)}
+}
+
+func point(s string) uint32 {
+	return crc32.ChecksumIEEE([]byte(s))
+}
+
+// Add places nodes on the circle. Adding a node twice has no effect.
+func (h *Hash) Add(nodes ...string) {
+	for _, node := range nodes {
+		for i := 0; i < h.replicas; i++ {
+			p := point(strconv.Itoa(i) + node)
+			if _, ok := h.owners[p]; !ok {
+				h.points = append(h.points, p)
+			}
+			h.owners[p] = node
+		}
+	}
+	sort.Slice(h.points, func(i, j int) bool { return h.points[i] < h.points[j] })
+}
+
+func (h *Hash) Remove(node string) {
+	points := h.points[:0]
+	for _, p := range h.points {
+		if h.owners[p] == node {
+			delete(h.owners, p)
+			continue
+		}
+		points = append(points, p)
+	}
+	h.points = points
+}
+
+func (h *Hash) Len() int {
+	return len(h.points) / h.replicas
+}
+
+// Get returns the node owning key, or false if there are no nodes.
+func (h *Hash) Get(key string) (string, bool) {
+	if len(h.points) == 0 {
+		return "", false
+	}
+	p := point(key)
+	i := sort.Search(len(h.points), func(i int) bool { return h.points[i] >= p })
+	if i == len(h.points) {
+		i = 0
+	}
+	return h.owners[h.points[i]], true
+}
+
+type config struct {
+	replicas int
+}
+
+type Option func(*config)
+
+// WithReplicas sets how many virtual nodes each server gets, 100 by
+// default. More even out the distribution at the cost of memory.
+func WithReplicas(n int) Option {
+	return func(c *config) {
+		c.replicas = n
+	}
+}
+
+// Ring is a client for a set of cache servers. It is safe for concurrent
+// use, including while nodes are added and removed.
+type Ring struct {
+	mu    sync.RWMutex
+	hash  *Hash
+	nodes map[string]cachepb.CacheClient
+}
+
+func New(opts ...Option) *Ring {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Ring{hash: NewHash(cfg.replicas), nodes: make(map[string]cachepb.CacheClient)}
+}
+
+// Add makes the server behind conn a node of the ring under name,
+// replacing any node of the same name. Closing conn is up to the caller.
+func (r *Ring) Add(name string, conn grpc.ClientConnInterface) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.nodes[name]; !ok {
+		r.hash.Add(name)
+	}
+	r.nodes[name] = cachepb.NewCacheClient(conn)
+}
+
+// Remove takes a node out of the ring; its keys move to the remaining
+// nodes, starting out empty there.
+func (r *Ring) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.nodes[name]; ok {
+		r.hash.Remove(name)
+		delete(r.nodes, name)
+	}
+}
+
+// Nodes returns the names of the nodes in the ring, sorted.
+func (r *Ring) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.nodes))
+	for name := range r.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Node returns the name of the node owning key.
+func (r *Ring) Node(key string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.hash.Get(key)
+}
+
+func (r *Ring) client(key string) (cachepb.CacheClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.hash.Get(key)
+	if !ok {
+		return nil, ErrNoNodes
+	}
+	return r.nodes[name], nil
+}
+
+// Get fetches key from its node. A missing key is reported as false with a
+// nil error.
+func (r *Ring) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	client, err := r.client(key)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := client.Get(ctx, &cachepb.GetRequest{Key: key})
+	if status.Code(err) == codes.NotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get %q: %w", key, err)
+	}
+	return resp.Value, true, nil
+}
+
+// Set stores value under key on its node. duration follows cache.Cache: 0
+// uses the server's default lifetime and cache.NoExpiration stores the
+// entry without expiry.
+func (r *Ring) Set(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	client, err := r.client(key)
+	if err != nil {
+		return err
+	}
+	ttl := duration.Milliseconds()
+	if duration == cache.NoExpiration {
+		ttl = -1
+	}
+	if _, err := client.Set(ctx, &cachepb.SetRequest{Key: key, Value: value, TtlMs: ttl}); err != nil {
+		return fmt.Errorf("set %q: %w", key, err)
+	}
+	return nil
+}
+
+func (r *Ring) Delete(ctx context.Context, key string) error {
+	client, err := r.client(key)
+	if err != nil {
+		return err
+	}
+	if _, err := client.Delete(ctx, &cachepb.DeleteRequest{Key: key}); err != nil {
+		return fmt.Errorf("delete %q: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package ring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	cache "go-in-memory-cache"
+	"go-in-memory-cache/grpcserver"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestHash(t *testing.T) {
+	h := NewHash(0)
+	if _, ok := h.Get("k"); ok {
+		t.Error("empty Hash owned a key")
+	}
+	h.Add("a", "b", "c")
+	h.Add("a")
+	if n := h.Len(); n != 3 {
+		t.Errorf("Len = %d, want 3", n)
+	}
+
+	owners := make(map[string]string)
+	counts := make(map[string]int)
+	for i := range 3000 {
+		key := fmt.Sprint("key", i)
+		node, ok := h.Get(key)
+		if !ok {
+			t.Fatalf("Get(%q) found no node", key)
+		}
+		owners[key] = node
+		counts[node]++
+	}
+	for node, n := range counts {
+		if n < 500 {
+			t.Errorf("node %s owns %d of 3000 keys", node, n)
+		}
+	}
+
+	h.Remove("b")
+	if n := h.Len(); n != 2 {
+		t.Errorf("Len after Remove = %d, want 2", n)
+	}
+	for key, was := range owners {
+		now, _ := h.Get(key)
+		switch {
+		case now == "b":
+			t.Fatalf("%q still maps to the removed node", key)
+		case was != "b" && now != was:
+			t.Errorf("%q moved from %s to %s though %s stayed", key, was, now, was)
+		}
+	}
+}
+
+// serve starts a grpcserver for c and returns a connection to it.
+func serve(t *testing.T, c *cache.Cache) *grpc.ClientConn {
+	t.Helper()
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	grpcserver.Register(srv, c)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRing(t *testing.T) {
+	ctx := context.Background()
+	r := New(WithReplicas(10))
+	if err := r.Set(ctx, "k", []byte("v"), 0); !errors.Is(err, ErrNoNodes) {
+		t.Fatalf("Set on an empty ring = %v, want %v", err, ErrNoNodes)
+	}
+
+	caches := map[string]*cache.Cache{"a": cache.New(0, 0), "b": cache.New(0, 0)}
+	for name, c := range caches {
+		r.Add(name, serve(t, c))
+	}
+	if got := r.Nodes(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Nodes = %v, want [a b]", got)
+	}
+
+	for i := range 20 {
+		key := fmt.Sprint("key", i)
+		if err := r.Set(ctx, key, []byte(key), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		node, _ := r.Node(key)
+		if _, ok := caches[node].Get(key); !ok {
+			t.Errorf("%q is not stored on its node %s", key, node)
+		}
+		if v, ok, err := r.Get(ctx, key); err != nil || !ok || string(v) != key {
+			t.Errorf("Get(%q) = %q, %v, %v", key, v, ok, err)
+		}
+	}
+
+	if err := r.Delete(ctx, "key0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := r.Get(ctx, "key0"); ok || err != nil {
+		t.Errorf("Get after Delete = %v, %v; want a miss", ok, err)
+	}
+}
+
+func TestRingSetNoExpiration(t *testing.T) {
+	c := cache.New(time.Minute, 0)
+	r := New()
+	r.Add("a", serve(t, c))
+	if err := r.Set(context.Background(), "k", []byte("v"), cache.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := c.TTL("k"); !ok || d != cache.NoExpiration {
+		t.Errorf("TTL = %v, %v; want NoExpiration", d, ok)
+	}
+}
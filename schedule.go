@@ -0,0 +1,79 @@
+package go_in_memory_cache
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// scheduledSet is a SetAt waiting for its activation time.
+type scheduledSet struct {
+	cancel chan struct{}
+}
+
+// SetAt stores value under key with the given ttl, but only from activateAt
+// on. Until then the key is missing: any current entry is deleted now, and
+// Get reports a miss. ttl counts from activation; 0 means the default
+// lifetime. A write to key before activateAt takes precedence and the
+// scheduled value is dropped; a later SetAt replaces the schedule. Pending
+// values are held in memory only and are discarded on Close. An activateAt
+// not in the future stores value immediately.
+func (c *Cache) SetAt(key string, value interface{}, activateAt time.Time, ttl time.Duration) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+	c.unschedule(key)
+	d := activateAt.Sub(c.clock.Now())
+	if d <= 0 {
+		return c.set(key, value, ttl, setAlways)
+	}
+	if err := c.Delete(key); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+
+	value = c.copyValue(value)
+	sched := &scheduledSet{cancel: make(chan struct{})}
+	c.schedMu.Lock()
+	if c.scheduled == nil {
+		c.scheduled = make(map[string]*scheduledSet)
+	}
+	c.scheduled[key] = sched
+	c.schedMu.Unlock()
+
+	// As in StartGC, the timer exists before the goroutine so a FakeClock
+	// advanced right away still fires it.
+	timer := c.clock.NewTimer(d)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+		case <-sched.cancel:
+			return
+		case <-c.stop:
+			return
+		}
+		c.schedMu.Lock()
+		current := c.scheduled[key] == sched
+		if current {
+			delete(c.scheduled, key)
+		}
+		c.schedMu.Unlock()
+		if current {
+			err := c.set(key, value, ttl, setIfAbsent)
+			if err != nil && !errors.Is(err, ErrKeyExists) && !errors.Is(err, ErrCacheClosed) && c.logger != nil {
+				c.logger.Error("scheduled set failed", slog.String("key", key), slog.Any("error", err))
+			}
+		}
+	}()
+	return nil
+}
+
+// unschedule cancels a pending SetAt for key.
+func (c *Cache) unschedule(key string) {
+	c.schedMu.Lock()
+	defer c.schedMu.Unlock()
+	if sched, ok := c.scheduled[key]; ok {
+		close(sched.cancel)
+		delete(c.scheduled, key)
+	}
+}
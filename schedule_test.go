@@ -0,0 +1,100 @@
+package go_in_memory_cache
+
+import (
+	"testing"
+	"time"
+)
+
+// scheduledCount returns how many SetAt calls are still waiting.
+func scheduledCount(c *Cache) int {
+	c.schedMu.Lock()
+	defer c.schedMu.Unlock()
+	return len(c.scheduled)
+}
+
+// waitScheduled waits for every pending SetAt of c to fire.
+func waitScheduled(t *testing.T, c *Cache) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for scheduledCount(c) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduled set did not fire")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSetAt(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Duration
+		// before runs after SetAt and before the clock reaches at.
+		before func(c *Cache) error
+		want   interface{}
+		found  bool
+	}{
+		{name: "activates", at: time.Minute, want: "scheduled", found: true},
+		{name: "past time stores now", at: -time.Minute, want: "scheduled", found: true},
+		{
+			name:   "write takes precedence",
+			at:     time.Minute,
+			before: func(c *Cache) error { return c.Set("key", "written", 0) },
+			want:   "written",
+			found:  true,
+		},
+		{
+			name:   "later SetAt replaces",
+			at:     time.Minute,
+			before: func(c *Cache) error { return c.SetAt("key", "replaced", epoch.Add(30*time.Second), 0) },
+			want:   "replaced",
+			found:  true,
+		},
+		{
+			name:   "frozen at activation",
+			at:     time.Minute,
+			before: func(c *Cache) error { c.Freeze(); return nil },
+			found:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(epoch)
+			c := New(0, 0, WithClock(clock))
+			defer c.Close()
+			if err := c.Set("key", "old", 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.SetAt("key", "scheduled", epoch.Add(tt.at), 0); err != nil {
+				t.Fatal(err)
+			}
+			if tt.at > 0 {
+				if _, ok := c.Get("key"); ok {
+					t.Fatal("key readable before activation")
+				}
+			}
+			if tt.before != nil {
+				if err := tt.before(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			clock.Advance(tt.at)
+			waitScheduled(t, c)
+			// The goroutine stores the value just after leaving the
+			// schedule; give it a moment, also so that a failing set
+			// has run before the test ends.
+			if !tt.found {
+				time.Sleep(10 * time.Millisecond)
+			}
+			deadline := time.Now().Add(time.Second)
+			got, ok := c.Get("key")
+			for ok != tt.found && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+				got, ok = c.Get("key")
+			}
+			if ok != tt.found || got != tt.want {
+				t.Errorf("Get = %v, %v; want %v, %v", got, ok, tt.want, tt.found)
+			}
+		})
+	}
+}
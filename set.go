@@ -0,0 +1,167 @@
+package go_in_memory_cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"slices"
+	"sync"
+)
+
+func init() {
+	gob.Register(map[string]bool(nil))
+}
+
+// setValue is the container behind the set operations. Its plain form is
+// map[string]bool with every member mapped to true.
+type setValue struct {
+	sync.RWMutex
+	members map[string]struct{}
+	bytes   int64
+}
+
+var setType = containerType[*setValue]{
+	name: "set",
+	make: func() *setValue { return &setValue{members: make(map[string]struct{})} },
+	adopt: func(v interface{}) (*setValue, bool) {
+		switch v := v.(type) {
+		case *setValue:
+			return v, true
+		case map[string]bool:
+			s := &setValue{members: make(map[string]struct{}, len(v))}
+			for member, ok := range v {
+				if ok {
+					s.add(member)
+				}
+			}
+			return s, true
+		}
+		return nil, false
+	},
+}
+
+func (s *setValue) add(member string) bool {
+	if _, ok := s.members[member]; ok {
+		return false
+	}
+	s.members[member] = struct{}{}
+	s.bytes += int64(len(member))
+	return true
+}
+
+func (s *setValue) rem(member string) bool {
+	if _, ok := s.members[member]; !ok {
+		return false
+	}
+	delete(s.members, member)
+	s.bytes -= int64(len(member))
+	return true
+}
+
+func (s *setValue) sorted() []string {
+	members := make([]string, 0, len(s.members))
+	for member := range s.members {
+		members = append(members, member)
+	}
+	slices.Sort(members)
+	return members
+}
+
+func (s *setValue) view() interface{} {
+	s.RLock()
+	defer s.RUnlock()
+	m := make(map[string]bool, len(s.members))
+	for member := range s.members {
+		m[member] = true
+	}
+	return m
+}
+
+func (s *setValue) clone() container {
+	s.RLock()
+	defer s.RUnlock()
+	c := &setValue{members: make(map[string]struct{}, len(s.members)), bytes: s.bytes}
+	for member := range s.members {
+		c.members[member] = struct{}{}
+	}
+	return c
+}
+
+func (*setValue) kind() string {
+	return "set"
+}
+
+func (s *setValue) len() int {
+	return len(s.members)
+}
+
+func (s *setValue) weight() int64 {
+	return s.bytes
+}
+
+// SAdd adds members to the set under key and returns how many were not
+// already in it. A missing key is created as a set with the default
+// lifetime; an existing one keeps its expiry. A key holding a
+// map[string]bool is treated as the set of its true keys. Any other value
+// fails with ErrTypeMismatch.
+func (c *Cache) SAdd(key string, members ...string) (int, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	var n int
+	err := withContainer(c, key, setType, true, func(s *setValue) (bool, error) {
+		for _, member := range members {
+			if s.add(member) {
+				n++
+			}
+		}
+		return n > 0, nil
+	})
+	return n, err
+}
+
+// SRem removes members from the set under key and returns how many were in
+// it. A set left empty is deleted.
+func (c *Cache) SRem(key string, members ...string) (int, error) {
+	var n int
+	err := withContainer(c, key, setType, false, func(s *setValue) (bool, error) {
+		for _, member := range members {
+			if s.rem(member) {
+				n++
+			}
+		}
+		return n > 0, nil
+	})
+	if errors.Is(err, ErrKeyNotFound) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// SIsMember reports whether member is in the set under key.
+func (c *Cache) SIsMember(key, member string) (bool, error) {
+	var ok bool
+	_, err := readContainer(c, key, setType, func(s *setValue) {
+		_, ok = s.members[member]
+	})
+	return ok, err
+}
+
+// SMembers returns the members of the set under key in sorted order, none
+// if it is missing.
+func (c *Cache) SMembers(key string) ([]string, error) {
+	var members []string
+	_, err := readContainer(c, key, setType, func(s *setValue) {
+		members = s.sorted()
+	})
+	return members, err
+}
+
+// SCard returns the number of members in the set under key, 0 if it is
+// missing.
+func (c *Cache) SCard(key string) (int, error) {
+	var n int
+	_, err := readContainer(c, key, setType, func(s *setValue) {
+		n = len(s.members)
+	})
+	return n, err
+}
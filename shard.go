@@ -0,0 +1,317 @@
+package go_in_memory_cache
+
+import "sync"
+
+type shard struct {
+	sync.RWMutex
+	items      map[string]Item
+	maxEntries int
+	maxCost    int64
+	cost       int64
+	weigher    Weigher
+	newPolicy  func() EvictionPolicy
+	clock      Clock
+	log        *appendLog
+	watch      *watchers
+	// grace is how long, in nanoseconds, expired entries are kept for
+	// stale reads before GC removes them.
+	grace int64
+	// undoWindow is how long, in nanoseconds, entries removed by
+	// DeleteAfter can still be restored by UndoDelete.
+	undoWindow int64
+	doomed     map[string]pendingDelete
+	undo       map[string]undoEntry
+	// tombstoneRetention is how long, in nanoseconds, tombstones of
+	// removed entries are kept; 0 keeps none.
+	tombstoneRetention int64
+	tombstones         map[string]Tombstone
+	// version is the last Version handed out by store.
+	version uint64
+	// tags indexes keys by the tags of their entries.
+	tags map[string]map[string]struct{}
+	// pinned holds keys exempt from eviction, mapped to whether they are
+	// also exempt from expiry.
+	pinned map[string]bool
+
+	expiries    map[string]*expiryEntry
+	expiryQueue expiryHeap
+
+	// policyMu guards policy so that readers holding only the read lock can
+	// still record accesses.
+	policyMu  sync.Mutex
+	policy    *priorityBuckets
+	admission *admission
+
+	// compressed, rawBytes and compressedBytes total the entries held in
+	// compressed form, for Stats.
+	compressed      int64
+	rawBytes        int64
+	compressedBytes int64
+}
+
+type keyedItem struct {
+	key  string
+	item Item
+}
+
+func newShard(maxEntries int, maxCost int64, weigher Weigher, policy func() EvictionPolicy) *shard {
+	s := &shard{
+		items:      make(map[string]Item),
+		maxEntries: maxEntries,
+		maxCost:    maxCost,
+		weigher:    weigher,
+		newPolicy:  policy,
+	}
+	if maxEntries > 0 || maxCost > 0 {
+		s.policy = newPriorityBuckets(policy)
+	}
+	return s
+}
+
+func (s *shard) weigh(key string, value interface{}) int64 {
+	if s.weigher == nil {
+		return 0
+	}
+	return s.weigher(key, value)
+}
+
+// live returns the unexpired item stored under key. The caller must hold
+// the lock.
+func (s *shard) live(key string) (Item, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return Item{}, false
+	}
+	if item.Expired > 0 && s.clock.Now().UnixNano() > item.Expired {
+		return Item{}, false
+	}
+	return item, true
+}
+
+// slide restarts the lifetime of a sliding entry and returns it. The
+// refreshed expiry is not written to the append log.
+func (s *shard) slide(key string) (Item, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	item, ok := s.live(key)
+	if !ok {
+		return Item{}, false
+	}
+	if item.Sliding > 0 {
+		item.Expired = s.clock.Now().Add(item.Sliding).UnixNano()
+		s.items[key] = item
+		s.setExpiry(key, item.Expired)
+	}
+	s.trackAccess(key)
+	item.meta.record(s.clock.Now())
+	return item, true
+}
+
+// store inserts item and returns the entries evicted to make room for it.
+// The caller must hold the write lock.
+func (s *shard) store(key string, item Item) []keyedItem {
+	item.cost = s.weigh(key, item.Value)
+	if s.pinned[key] {
+		item.Expired, item.Sliding = 0, 0
+	}
+	if item.meta == nil {
+		item.meta = &accessMeta{}
+	}
+	s.version++
+	item.Version = s.version
+	evicted := s.makeRoom(key, item.cost)
+	if old, ok := s.items[key]; ok {
+		s.untag(key, old.Tags)
+		s.cost -= old.cost
+		s.countCompressed(old, -1)
+	}
+	s.items[key] = item
+	s.cost += item.cost
+	s.countCompressed(item, 1)
+	delete(s.doomed, key)
+	delete(s.undo, key)
+	delete(s.tombstones, key)
+	s.tag(key, item.Tags)
+	s.setExpiry(key, item.Expired)
+	if _, ok := s.pinned[key]; !ok {
+		s.trackAdd(key, item.Priority)
+	}
+	if s.log != nil {
+		logged := item
+		logged.Value = plain(item.Value)
+		s.log.append(logRecord{Op: logSet, Key: key, Item: logged})
+	}
+	s.watch.notify(EventSet, key, item)
+	return evicted
+}
+
+// remove deletes key and reports whether it was present. The caller must
+// hold the write lock.
+func (s *shard) remove(key string) (Item, bool) {
+	return s.removeAs(key, EventDelete)
+}
+
+// removeAs is remove reporting the deletion to watchers as t.
+func (s *shard) removeAs(key string, t EventType) (Item, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return Item{}, false
+	}
+	delete(s.items, key)
+	s.cost -= item.cost
+	s.countCompressed(item, -1)
+	s.untag(key, item.Tags)
+	s.clearExpiry(key)
+	s.trackRemove(key)
+	delete(s.pinned, key)
+	delete(s.doomed, key)
+	s.bury(key, item, t)
+	if s.log != nil {
+		s.log.append(logRecord{Op: logDelete, Key: key})
+	}
+	s.watch.notify(t, key, item)
+	return item, true
+}
+
+func (s *shard) clearItems(keys []string) (removed []keyedItem) {
+	s.Lock()
+	defer s.Unlock()
+	for _, key := range keys {
+		if item, ok := s.remove(key); ok {
+			removed = append(removed, keyedItem{key: key, item: item})
+		}
+	}
+	return
+}
+
+// drain empties the shard and returns everything it held. Nothing is
+// written to the append log.
+func (s *shard) drain() []keyedItem {
+	s.Lock()
+	defer s.Unlock()
+	return s.drainLocked()
+}
+
+func (s *shard) drainLocked() (removed []keyedItem) {
+	for key, item := range s.items {
+		removed = append(removed, keyedItem{key: key, item: item})
+	}
+	s.items = make(map[string]Item)
+	s.cost = 0
+	s.compressed, s.rawBytes, s.compressedBytes = 0, 0, 0
+	s.tags = nil
+	s.pinned = nil
+	s.doomed = nil
+	s.undo = nil
+	s.tombstones = nil
+	s.expiries = nil
+	s.expiryQueue = nil
+	if s.policy != nil {
+		s.policyMu.Lock()
+		s.policy = newPriorityBuckets(s.newPolicy)
+		s.policyMu.Unlock()
+	}
+	return
+}
+
+func (s *shard) trackAdd(key string, p Priority) {
+	if s.policy == nil {
+		return
+	}
+	s.policyMu.Lock()
+	s.policy.add(key, p)
+	s.policyMu.Unlock()
+}
+
+func (s *shard) trackAccess(key string) {
+	if s.policy == nil {
+		return
+	}
+	s.policyMu.Lock()
+	s.policy.access(key)
+	if s.admission != nil {
+		s.admission.record(key)
+	}
+	s.policyMu.Unlock()
+}
+
+func (s *shard) trackRemove(key string) {
+	if s.policy == nil {
+		return
+	}
+	s.policyMu.Lock()
+	s.policy.remove(key)
+	s.policyMu.Unlock()
+}
+
+// makeRoom evicts entries until an entry of the given cost can be stored
+// under key without exceeding maxEntries or maxCost. It must be called with
+// the write lock held.
+func (s *shard) makeRoom(key string, cost int64) (evicted []keyedItem) {
+	if s.policy == nil {
+		return nil
+	}
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+
+	for s.overflows(key, cost) {
+		victim, ok := s.policy.victim()
+		if !ok {
+			return
+		}
+		s.policy.remove(victim)
+		item, ok := s.items[victim]
+		if !ok {
+			continue
+		}
+		evicted = append(evicted, keyedItem{key: victim, item: item})
+		delete(s.items, victim)
+		s.cost -= item.cost
+		s.countCompressed(item, -1)
+		s.untag(victim, item.Tags)
+		s.clearExpiry(victim)
+		delete(s.doomed, victim)
+		s.bury(victim, item, EventEvict)
+		if s.log != nil {
+			s.log.append(logRecord{Op: logDelete, Key: victim})
+		}
+		s.watch.notify(EventEvict, victim, item)
+	}
+	return
+}
+
+func (s *shard) overflows(key string, cost int64) bool {
+	old, exists := s.items[key]
+	if s.maxEntries > 0 && !exists && len(s.items) >= s.maxEntries {
+		return true
+	}
+	return s.maxCost > 0 && s.cost-old.cost+cost > s.maxCost
+}
+
+func (s *shard) tag(key string, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if s.tags == nil {
+		s.tags = make(map[string]map[string]struct{})
+	}
+	for _, t := range tags {
+		keys, ok := s.tags[t]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[t] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+func (s *shard) untag(key string, tags []string) {
+	for _, t := range tags {
+		keys := s.tags[t]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(s.tags, t)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+)
+
+// Shutdown closes the cache gracefully. It freezes the cache and pauses GC
+// so entries stay as they are, pushes queued WithWriteBehind writes to the
+// store, runs the WithShutdownHook hooks in the order they were added,
+// syncs the append log and finally calls Close. Reads keep working until
+// then, so hooks can persist the cache with SaveFile or SaveSnapshot. Hook
+// errors do not stop later hooks and are joined into the result.
+//
+// If ctx is done first, Shutdown skips whatever is left but Close, which it
+// starts in the background, and returns ctx's error.
+func (c *Cache) Shutdown(ctx context.Context) error {
+	if c.closed.Load() {
+		return ErrCacheClosed
+	}
+	c.Freeze()
+	c.PauseGC()
+
+	var errs []error
+	if err := c.FlushWrites(ctx); err != nil && !errors.Is(err, ErrCacheClosed) {
+		errs = append(errs, err)
+	}
+	for _, hook := range c.shutdownHooks {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := hook(ctx, c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if ctx.Err() == nil {
+		if err := c.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.Close() }()
+	select {
+	case err := <-done:
+		if err != nil && !errors.Is(err, ErrCacheClosed) {
+			errs = append(errs, err)
+		}
+	case <-ctx.Done():
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+package go_in_memory_cache
+
+import "hash/maphash"
+
+const sketchDepth = 4
+
+// frequencySketch is a count-min sketch with 4-bit saturating counters that
+// are halved periodically, so estimates favour recent popularity.
+type frequencySketch struct {
+	seeds     [sketchDepth]maphash.Seed
+	rows      [sketchDepth][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+func newFrequencySketch(width int) *frequencySketch {
+	size := 1
+	for size < width {
+		size <<= 1
+	}
+	s := &frequencySketch{
+		mask:    uint64(size - 1),
+		resetAt: size * 10,
+	}
+	for i := range s.rows {
+		s.seeds[i] = maphash.MakeSeed()
+		s.rows[i] = make([]uint8, size)
+	}
+	return s
+}
+
+func (s *frequencySketch) increment(key string) {
+	for i := range s.rows {
+		idx := maphash.String(s.seeds[i], key) & s.mask
+		if s.rows[i][idx] < 15 {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *frequencySketch) estimate(key string) uint8 {
+	lowest := uint8(15)
+	for i := range s.rows {
+		idx := maphash.String(s.seeds[i], key) & s.mask
+		if v := s.rows[i][idx]; v < lowest {
+			lowest = v
+		}
+	}
+	return lowest
+}
+
+func (s *frequencySketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,94 @@
+package go_in_memory_cache
+
+import (
+	"errors"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of a cache's live entries, taken with
+// Cache.Snapshot and reinstated with Cache.Restore. Values are copied only
+// when the cache was created WithValueCopy; otherwise the snapshot shares
+// them with the cache.
+type Snapshot struct {
+	Taken time.Time
+	items map[string]Item
+}
+
+// Len returns the number of entries in the snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.items)
+}
+
+// Snapshot captures every live entry. All shards are read-locked together,
+// so the snapshot reflects a single moment.
+func (c *Cache) Snapshot() *Snapshot {
+	snap := &Snapshot{Taken: c.clock.Now(), items: make(map[string]Item)}
+	if c.closed.Load() {
+		return snap
+	}
+
+	for _, s := range c.shards {
+		s.RLock()
+	}
+	now := snap.Taken.UnixNano()
+	for _, s := range c.shards {
+		for key, item := range s.items {
+			if item.Expired > 0 && now > item.Expired {
+				continue
+			}
+			snap.items[key] = item
+		}
+	}
+	for _, s := range c.shards {
+		s.RUnlock()
+	}
+
+	for key, item := range snap.items {
+		item.meta = nil
+		if item.Compression == Uncompressed {
+			item.Value = c.copyValue(item.Value)
+		}
+		snap.items[key] = item
+	}
+	return snap
+}
+
+// Restore replaces the contents of the cache with snap, holding all shard
+// locks so no reader observes a mix of old and restored entries. Entries
+// that expired since the snapshot was taken are skipped. Replaced entries
+// are passed to the OnEvicted callback.
+func (c *Cache) Restore(snap *Snapshot) error {
+	if snap == nil {
+		return errors.New("nil snapshot")
+	}
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	now := c.clock.Now().UnixNano()
+	c.lockAll()
+	var removed, evicted []keyedItem
+	for _, s := range c.shards {
+		removed = append(removed, s.drainLocked()...)
+	}
+	if c.log != nil {
+		c.log.append(logRecord{Op: logFlush})
+	}
+	for _, r := range removed {
+		c.watch.notify(EventDelete, r.key, r.item)
+	}
+	for key, item := range snap.items {
+		if item.Expired > 0 && now > item.Expired {
+			continue
+		}
+		if item.Compression == Uncompressed {
+			item.Value = c.copyValue(item.Value)
+		}
+		evicted = append(evicted, c.shardFor(key).store(key, item)...)
+	}
+	c.unlockAll()
+
+	c.deleted(removed)
+	c.overflowed(evicted)
+	return nil
+}
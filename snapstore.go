@@ -0,0 +1,207 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SnapshotStore holds named snapshots written by SaveSnapshot, such as
+// files in a directory or objects in a bucket. Stores that also implement
+// SnapshotDeleter have old snapshots pruned per WithSnapshotRetention.
+type SnapshotStore interface {
+	// Put stores the snapshot read from r under name, replacing any
+	// snapshot of that name only once r has been read completely.
+	Put(ctx context.Context, name string, r io.Reader) error
+	// Get opens the snapshot stored under name.
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the names of all stored snapshots in any order.
+	List(ctx context.Context) ([]string, error)
+}
+
+// SnapshotDeleter is implemented by stores that can remove snapshots.
+type SnapshotDeleter interface {
+	Delete(ctx context.Context, name string) error
+}
+
+const snapshotExt = ".gob"
+
+// snapshotName names snapshots so that they sort oldest first.
+func snapshotName(t time.Time) string {
+	return "snapshot-" + t.UTC().Format("20060102T150405.000000000Z") + snapshotExt
+}
+
+// SaveSnapshot writes the live entries to store under a name derived from
+// the current time, in the format read by Load, and returns that name.
+func (c *Cache) SaveSnapshot(ctx context.Context, store SnapshotStore) (string, error) {
+	if c.closed.Load() {
+		return "", ErrCacheClosed
+	}
+
+	name := snapshotName(c.clock.Now())
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.Save(pw))
+	}()
+	err := store.Put(ctx, name, pr)
+	pr.CloseWithError(err)
+	if err != nil {
+		return "", err
+	}
+	return name, c.pruneSnapshots(ctx, store)
+}
+
+// LoadLatestSnapshot loads the newest snapshot in store that can be read,
+// trying older ones if it fails. It returns os.ErrNotExist if store holds
+// no snapshot.
+func (c *Cache) LoadLatestSnapshot(ctx context.Context, store SnapshotStore) error {
+	names, err := snapshotNames(ctx, store)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return os.ErrNotExist
+	}
+
+	var errs []error
+	for i := len(names) - 1; i >= 0; i-- {
+		r, err := store.Get(ctx, names[i])
+		if err == nil {
+			err = c.Load(r)
+			r.Close()
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Cache) pruneSnapshots(ctx context.Context, store SnapshotStore) error {
+	d, ok := store.(SnapshotDeleter)
+	if !ok || c.snapshotKeep < 1 {
+		return nil
+	}
+	names, err := snapshotNames(ctx, store)
+	if err != nil {
+		return err
+	}
+	for len(names) > c.snapshotKeep {
+		if err := d.Delete(ctx, names[0]); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// snapshotNames lists the snapshots in store written by SaveSnapshot,
+// oldest first.
+func snapshotNames(ctx context.Context, store SnapshotStore) ([]string, error) {
+	all, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range all {
+		if strings.HasPrefix(name, "snapshot-") && strings.HasSuffix(name, snapshotExt) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// FileSnapshotStore is a SnapshotStore keeping each snapshot as a file in
+// a directory.
+type FileSnapshotStore struct {
+	dir string
+}
+
+// NewFileSnapshotStore returns a store writing to dir, which is created on
+// first Put if missing.
+func NewFileSnapshotStore(dir string) *FileSnapshotStore {
+	return &FileSnapshotStore{dir: dir}
+}
+
+func (s *FileSnapshotStore) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) {
+		return "", errors.New("invalid snapshot name")
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// Put writes r to a temporary file, syncs it and renames it to name.
+func (s *FileSnapshotStore) Put(ctx context.Context, name string, r io.Reader) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *FileSnapshotStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *FileSnapshotStore) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasSuffix(e.Name(), ".tmp") {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (s *FileSnapshotStore) Delete(ctx context.Context, name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
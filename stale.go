@@ -0,0 +1,139 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// stale returns the entry stored under key if it has expired no longer
+// than the shard's grace period ago.
+func (s *shard) stale(key string) (Item, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	item, ok := s.items[key]
+	if !ok || item.Expired == 0 {
+		return Item{}, false
+	}
+	if _, ok := s.doomed[key]; ok {
+		return Item{}, false
+	}
+	now := s.clock.Now().UnixNano()
+	if now <= item.Expired || now > item.Expired+s.grace {
+		return Item{}, false
+	}
+	return item, true
+}
+
+// GetStale returns the value under key even if it has expired, as long as
+// GC has not removed it yet, and how long ago it expired; 0 for a live
+// entry. Unlike Get it never calls the Loader and is not counted in Stats,
+// so callers can fall back to it when the origin is down. Caches created
+// WithStaleWhileRevalidate keep expired entries for that window; otherwise
+// they last until the next GC run or, WithLazyExpiry, the next read.
+func (c *Cache) GetStale(key string) (interface{}, time.Duration, bool) {
+	if c.closed.Load() {
+		return nil, 0, false
+	}
+
+	s := c.shardFor(key)
+	s.RLock()
+	item, ok := s.items[key]
+	_, doomed := s.doomed[key]
+	s.RUnlock()
+	if !ok || item.Negative {
+		return nil, 0, false
+	}
+
+	var ago time.Duration
+	if now := c.clock.Now().UnixNano(); item.Expired > 0 && now > item.Expired {
+		if doomed {
+			// Removed by DeleteAfter, not expired.
+			return nil, 0, false
+		}
+		ago = time.Duration(now - item.Expired)
+	}
+	return c.valueOf(item), ago, true
+}
+
+// serveStale returns a recently expired value for key and starts a
+// background refresh, when the cache was created WithStaleWhileRevalidate.
+func (c *Cache) serveStale(key string) (interface{}, bool) {
+	if c.staleWindow <= 0 || c.loader == nil {
+		return nil, false
+	}
+	item, ok := c.shardFor(key).stale(key)
+	if !ok || item.Negative {
+		return nil, false
+	}
+	c.refresh(key, item.Created)
+	return c.valueOf(item), true
+}
+
+// refreshIfDue starts a background refresh of item once less than the
+// WithRefreshAhead fraction of its lifetime remains.
+func (c *Cache) refreshIfDue(key string, item Item) {
+	if c.refreshAhead <= 0 || c.loader == nil || item.Expired == 0 || item.Sliding > 0 {
+		return
+	}
+	lifetime := item.Expired - item.Created.UnixNano()
+	remaining := item.Expired - c.clock.Now().UnixNano()
+	if float64(remaining) < c.refreshAhead*float64(lifetime) {
+		c.refresh(key, item.Created)
+	}
+}
+
+// refresh reloads key in the background unless a refresh is already in
+// flight. The result is stored only if key still holds the entry created at
+// created, or nothing live, so that a concurrent Set is not overwritten.
+func (c *Cache) refresh(key string, created time.Time) {
+	c.refreshMu.Lock()
+	if _, ok := c.refreshing[key]; ok {
+		c.refreshMu.Unlock()
+		return
+	}
+	if c.refreshing == nil {
+		c.refreshing = make(map[string]struct{})
+	}
+	c.refreshing[key] = struct{}{}
+	c.refreshMu.Unlock()
+
+	go func() {
+		defer func() {
+			c.refreshMu.Lock()
+			delete(c.refreshing, key)
+			c.refreshMu.Unlock()
+		}()
+
+		c.flights.Do(key, func() (interface{}, error) {
+			value, ttl, err := c.loader.Load(context.Background(), key)
+			if err != nil {
+				if c.logger != nil {
+					c.logger.Warn("background refresh failed", slog.String("key", key), slog.Any("error", err))
+				}
+				return nil, err
+			}
+			c.fill(key, c.newItem(key, value, ttl, false), created)
+			return value, nil
+		})
+	}()
+}
+
+func (c *Cache) fill(key string, item Item, created time.Time) {
+	if c.closed.Load() {
+		return
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+	if cur, ok := s.live(key); ok && !cur.Created.Equal(created) {
+		s.Unlock()
+		return
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.stats.sets.Add(1)
+	c.overflowed(evicted)
+}
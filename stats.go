@@ -0,0 +1,107 @@
+package go_in_memory_cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Sets      uint64
+	Deletes   uint64
+	Evictions uint64
+	Expired   uint64
+	Rejected  uint64
+	Entries   int
+	Cost      int64
+	MaxCost   int64
+	Pinned    int
+
+	GCRuns     uint64
+	GCDuration time.Duration
+	// LastGC describes the most recent GC cycle; it is zero before the
+	// first one.
+	LastGC GCReport
+
+	// Compressed counts the entries currently stored compressed; RawBytes
+	// and CompressedBytes are their total sizes before and after
+	// compression.
+	Compressed      uint64
+	RawBytes        uint64
+	CompressedBytes uint64
+}
+
+type counters struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	sets      atomic.Uint64
+	deletes   atomic.Uint64
+	evictions atomic.Uint64
+	expired   atomic.Uint64
+	rejected  atomic.Uint64
+	gcRuns    atomic.Uint64
+	gcNanos   atomic.Int64
+}
+
+func (c *Cache) Stats() Stats {
+	compressed, rawBytes, compressedBytes := c.compression()
+	return Stats{
+		Hits:      c.stats.hits.Load(),
+		Misses:    c.stats.misses.Load(),
+		Sets:      c.stats.sets.Load(),
+		Deletes:   c.stats.deletes.Load(),
+		Evictions: c.stats.evictions.Load(),
+		Expired:   c.stats.expired.Load(),
+		Rejected:  c.stats.rejected.Load(),
+		Entries:   c.Count(),
+		Cost:      c.cost(),
+		MaxCost:   c.maxCost,
+		Pinned:    c.pinnedCount(),
+
+		GCRuns:     c.stats.gcRuns.Load(),
+		GCDuration: time.Duration(c.stats.gcNanos.Load()),
+		LastGC:     c.lastGCReport(),
+
+		Compressed:      compressed,
+		RawBytes:        rawBytes,
+		CompressedBytes: compressedBytes,
+	}
+}
+
+func (c *Cache) lastGCReport() GCReport {
+	if r := c.lastGC.Load(); r != nil {
+		return *r
+	}
+	return GCReport{}
+}
+
+func (c *Cache) hit(ok bool) {
+	if ok {
+		c.stats.hits.Add(1)
+	} else {
+		c.stats.misses.Add(1)
+	}
+}
+
+func (c *Cache) cost() int64 {
+	var n int64
+	for _, s := range c.shards {
+		s.RLock()
+		n += s.cost
+		s.RUnlock()
+	}
+	return n
+}
+
+// compression totals the entries held compressed across the shards.
+func (c *Cache) compression() (entries, rawBytes, compressedBytes uint64) {
+	for _, s := range c.shards {
+		s.RLock()
+		entries += uint64(s.compressed)
+		rawBytes += uint64(s.rawBytes)
+		compressedBytes += uint64(s.compressedBytes)
+		s.RUnlock()
+	}
+	return
+}
@@ -0,0 +1,293 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Store is a backing store that writes are propagated to, configured with
+// WithWriteThrough or WithWriteBehind. Every change made through the
+// cache's API is propagated, including Import and Merge; expiry, eviction,
+// Flush, Load, ImportLocal and broadcast invalidations only affect the
+// cache.
+type Store interface {
+	Put(ctx context.Context, key string, value interface{}) error
+	Delete(ctx context.Context, key string) error
+}
+
+// ExpiringStore is a Store that also records when entries expire. If the
+// Store given to WithWriteThrough or WithWriteBehind implements it,
+// PutExpiring is called instead of Put. expires is zero for entries that
+// never expire.
+type ExpiringStore interface {
+	Store
+	PutExpiring(ctx context.Context, key string, value interface{}, expires time.Time) error
+}
+
+// put writes to store, passing expired on if store is an ExpiringStore.
+func put(ctx context.Context, store Store, key string, value interface{}, expired int64) error {
+	es, ok := store.(ExpiringStore)
+	if !ok {
+		return store.Put(ctx, key, value)
+	}
+	var expires time.Time
+	if expired > 0 {
+		expires = time.Unix(0, expired)
+	}
+	return es.PutExpiring(ctx, key, value, expires)
+}
+
+// WriteBehindConfig tunes WithWriteBehind. Zero fields take the defaults
+// noted below.
+type WriteBehindConfig struct {
+	// Workers is the number of goroutines writing to the store, 1 by
+	// default. Writes to one key always go through the same worker.
+	Workers int
+	// BatchSize is the number of pending keys that triggers a flush before
+	// FlushInterval elapses, 100 by default.
+	BatchSize int
+	// FlushInterval is how long writes are held and coalesced, 1s by
+	// default.
+	FlushInterval time.Duration
+	// MaxRetries is how often a failed write is retried, 3 by default. A
+	// negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for each
+	// further attempt, 100ms by default.
+	RetryBackoff time.Duration
+	// OnError is called with writes that still fail after all retries.
+	OnError func(key string, err error)
+}
+
+type pendingWrite struct {
+	key     string
+	value   interface{}
+	expired int64
+	delete  bool
+	// barrier, when set, marks a sync point rather than a write.
+	barrier *sync.WaitGroup
+}
+
+// writeBehind queues writes, coalescing them per key, and flushes them to
+// the store in the background.
+type writeBehind struct {
+	store  Store
+	cfg    WriteBehindConfig
+	clock  Clock
+	logger *slog.Logger
+	seed   maphash.Seed
+
+	mu      sync.Mutex
+	pending map[string]pendingWrite
+
+	// flushMu serializes flushes so that writes to a key are dispatched in
+	// order, and guards stopped.
+	flushMu sync.Mutex
+	stopped bool
+
+	kick chan struct{}
+	stop chan struct{}
+	work []chan pendingWrite
+	done sync.WaitGroup
+}
+
+func newWriteBehind(store Store, cfg WriteBehindConfig) *writeBehind {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	return &writeBehind{
+		store:   store,
+		cfg:     cfg,
+		seed:    maphash.MakeSeed(),
+		pending: make(map[string]pendingWrite),
+		kick:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+func (w *writeBehind) start(clock Clock) {
+	w.clock = clock
+	w.work = make([]chan pendingWrite, w.cfg.Workers)
+	for i := range w.work {
+		w.work[i] = make(chan pendingWrite, w.cfg.BatchSize)
+		w.done.Add(1)
+		go w.worker(w.work[i])
+	}
+
+	timer := clock.NewTimer(w.cfg.FlushInterval)
+	w.done.Add(1)
+	go func() {
+		defer w.done.Done()
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C():
+				w.flush()
+				timer.Reset(w.cfg.FlushInterval)
+			case <-w.kick:
+				w.flush()
+			case <-w.stop:
+				w.flushMu.Lock()
+				w.flushLocked()
+				for _, ch := range w.work {
+					close(ch)
+				}
+				w.stopped = true
+				w.flushMu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+func (w *writeBehind) enqueue(p pendingWrite) {
+	w.mu.Lock()
+	w.pending[p.key] = p
+	full := len(w.pending) >= w.cfg.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *writeBehind) flush() {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	w.flushLocked()
+}
+
+func (w *writeBehind) flushLocked() {
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = make(map[string]pendingWrite)
+	w.mu.Unlock()
+
+	for key, p := range batch {
+		w.work[maphash.String(w.seed, key)%uint64(len(w.work))] <- p
+	}
+}
+
+func (w *writeBehind) worker(ch <-chan pendingWrite) {
+	defer w.done.Done()
+	for p := range ch {
+		if p.barrier != nil {
+			p.barrier.Done()
+			continue
+		}
+		backoff := w.cfg.RetryBackoff
+		for attempt := 0; ; attempt++ {
+			err := w.write(p)
+			if err == nil {
+				break
+			}
+			if attempt >= w.cfg.MaxRetries {
+				if w.cfg.OnError != nil {
+					w.cfg.OnError(p.key, err)
+				} else if w.logger != nil {
+					w.logger.Error("write-behind failed", slog.String("key", p.key), slog.Int("attempts", attempt+1), slog.Any("error", err))
+				}
+				break
+			}
+			timer := w.clock.NewTimer(backoff)
+			<-timer.C()
+			backoff *= 2
+		}
+	}
+}
+
+func (w *writeBehind) write(p pendingWrite) error {
+	if p.delete {
+		return w.store.Delete(context.Background(), p.key)
+	}
+	return put(context.Background(), w.store, p.key, p.value, p.expired)
+}
+
+// sync flushes pending writes and waits until the workers have attempted
+// them, or until ctx is done.
+func (w *writeBehind) sync(ctx context.Context) error {
+	w.flushMu.Lock()
+	if w.stopped {
+		w.flushMu.Unlock()
+		return ErrCacheClosed
+	}
+	w.flushLocked()
+	var barrier sync.WaitGroup
+	barrier.Add(len(w.work))
+	for _, ch := range w.work {
+		ch <- pendingWrite{barrier: &barrier}
+	}
+	w.flushMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		barrier.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close flushes everything still pending and waits for the workers.
+func (w *writeBehind) close() {
+	close(w.stop)
+	w.done.Wait()
+}
+
+// FlushWrites pushes writes queued by WithWriteBehind to the store and
+// waits until they have been attempted or ctx is done. Writes that fail
+// after all retries are reported to OnError as usual.
+func (c *Cache) FlushWrites(ctx context.Context) error {
+	if c.behind == nil {
+		return nil
+	}
+	return c.behind.sync(ctx)
+}
+
+// storePut propagates a write to the backing store. It is called with the
+// key's shard lock held so the store sees writes to a key in cache order.
+// With write-through the store's error is returned and the caller must not
+// cache the item. Negative entries are never propagated.
+func (c *Cache) storePut(ctx context.Context, key string, item Item) error {
+	switch {
+	case item.Negative:
+	case c.behind != nil:
+		c.behind.enqueue(pendingWrite{key: key, value: c.valueOf(item), expired: item.Expired})
+	case c.store != nil:
+		return put(ctx, c.store, key, c.valueOf(item), item.Expired)
+	}
+	return nil
+}
+
+// storeDelete is storePut for deletions.
+func (c *Cache) storeDelete(ctx context.Context, key string) error {
+	switch {
+	case c.behind != nil:
+		c.behind.enqueue(pendingWrite{key: key, delete: true})
+	case c.store != nil:
+		return c.store.Delete(ctx, key)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingStore is a Store that logs the writes it receives and can be
+// made to reject them.
+type recordingStore struct {
+	mu     sync.Mutex
+	ops    []string
+	reject bool
+}
+
+var errRejected = errors.New("rejected by store")
+
+func (s *recordingStore) Put(ctx context.Context, key string, value interface{}) error {
+	return s.record(fmt.Sprintf("put %s=%v", key, value))
+}
+
+func (s *recordingStore) Delete(ctx context.Context, key string) error {
+	return s.record("delete " + key)
+}
+
+func (s *recordingStore) record(op string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reject {
+		return errRejected
+	}
+	s.ops = append(s.ops, op)
+	return nil
+}
+
+func (s *recordingStore) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ops := s.ops
+	s.ops = nil
+	return ops
+}
+
+func TestWriteThroughPropagation(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(c *Cache) error
+		want []string
+	}{
+		{
+			name: "Increment",
+			op: func(c *Cache) error {
+				_, err := c.Increment("n", 2)
+				return err
+			},
+			want: []string{"put n=3"},
+		},
+		{
+			name: "Update",
+			op: func(c *Cache) error {
+				_, err := c.Update("a", func(old interface{}, exists bool) (interface{}, error) {
+					return fmt.Sprint(old, "!"), nil
+				}, 0)
+				return err
+			},
+			want: []string{"put a=1!"},
+		},
+		{
+			name: "GetSet",
+			op: func(c *Cache) error {
+				c.GetSet("a", 2, 0)
+				return nil
+			},
+			want: []string{"put a=2"},
+		},
+		{
+			name: "CompareAndSwap",
+			op: func(c *Cache) error {
+				c.CompareAndSwap("a", 1, 2, 0)
+				return nil
+			},
+			want: []string{"put a=2"},
+		},
+		{
+			name: "CompareAndDelete",
+			op: func(c *Cache) error {
+				c.CompareAndDelete("a", 1)
+				return nil
+			},
+			want: []string{"delete a"},
+		},
+		{
+			name: "Pop",
+			op: func(c *Cache) error {
+				c.Pop("a")
+				return nil
+			},
+			want: []string{"delete a"},
+		},
+		{
+			name: "Swap",
+			op:   func(c *Cache) error { return c.Swap("a", "n") },
+			want: []string{"put a=1", "put n=1"},
+		},
+		{
+			name: "Rename",
+			op:   func(c *Cache) error { return c.Rename("a", "b") },
+			want: []string{"put b=1", "delete a"},
+		},
+		{
+			name: "Copy",
+			op:   func(c *Cache) error { return c.Copy("a", "b") },
+			want: []string{"put b=1"},
+		},
+		{
+			name: "Touch",
+			op:   func(c *Cache) error { return c.Touch("a", time.Minute) },
+			want: []string{"put a=1"},
+		},
+		{
+			name: "ExpireAt",
+			op:   func(c *Cache) error { return c.ExpireAt("a", epoch.Add(time.Minute)) },
+			want: []string{"put a=1"},
+		},
+		{
+			name: "GetOrSet",
+			op: func(c *Cache) error {
+				c.GetOrSet("b", 2, 0)
+				return nil
+			},
+			want: []string{"put b=2"},
+		},
+		{
+			name: "Import",
+			op: func(c *Cache) error {
+				return c.Import(map[string]Item{"a": {Value: 2}, "b": {Value: 3}}, false)
+			},
+			want: []string{"put b=3"},
+		},
+		{
+			name: "ImportLocal",
+			op: func(c *Cache) error {
+				return c.ImportLocal(map[string]Item{"b": {Value: 3}}, false)
+			},
+		},
+		{
+			name: "Merge",
+			op: func(c *Cache) error {
+				other := New(0, 0)
+				if err := other.Set("b", 2, 0); err != nil {
+					return err
+				}
+				_, err := c.Merge(other, MergeOverwrite)
+				return err
+			},
+			want: []string{"put b=2"},
+		},
+		{
+			name: "RPush",
+			op: func(c *Cache) error {
+				_, err := c.RPush("l", "x")
+				return err
+			},
+			want: []string{"put l=[x]"},
+		},
+		{
+			name: "LPop last element",
+			op: func(c *Cache) error {
+				if _, err := c.RPush("l", "x"); err != nil {
+					return err
+				}
+				_, err := c.LPop("l")
+				return err
+			},
+			want: []string{"put l=[x]", "delete l"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStore{}
+			c := New(0, 0, WithClock(NewFakeClock(epoch)), WithWriteThrough(store))
+			if err := c.Set("a", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Set("n", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			store.take()
+
+			if err := tt.op(c); err != nil {
+				t.Fatal(err)
+			}
+			if got := store.take(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("store saw %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteThroughRejection(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(c *Cache) error
+	}{
+		{name: "Set", op: func(c *Cache) error { return c.Set("a", 2, 0) }},
+		{
+			name: "CompareAndSwap",
+			op: func(c *Cache) error {
+				if c.CompareAndSwap("a", 1, 2, 0) {
+					return nil
+				}
+				return errRejected
+			},
+		},
+		{
+			name: "Pop",
+			op: func(c *Cache) error {
+				if _, ok := c.Pop("a"); ok {
+					return nil
+				}
+				return errRejected
+			},
+		},
+		{name: "Rename", op: func(c *Cache) error { return c.Rename("a", "b") }},
+		{
+			name: "Increment",
+			op: func(c *Cache) error {
+				_, err := c.Increment("a", 1)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &recordingStore{}
+			c := New(0, 0, WithWriteThrough(store))
+			if err := c.Set("a", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			store.reject = true
+
+			if err := tt.op(c); err == nil {
+				t.Fatal("rejected write succeeded")
+			}
+			if v, ok := c.Get("a"); !ok || v != 1 {
+				t.Errorf("Get(a) = %v, %v; want 1, true", v, ok)
+			}
+			if _, ok := c.Get("b"); ok {
+				t.Error("rejected write created b")
+			}
+		})
+	}
+}
+
+func TestWriteThroughRejectedContainer(t *testing.T) {
+	store := &recordingStore{}
+	c := New(0, 0, WithWriteThrough(store))
+	if _, err := c.RPush("l", "x"); err != nil {
+		t.Fatal(err)
+	}
+	store.reject = true
+
+	if _, err := c.RPush("l", "y"); !errors.Is(err, errRejected) {
+		t.Fatalf("RPush = %v, want %v", err, errRejected)
+	}
+	if got, _ := c.LRange("l", 0, -1); !reflect.DeepEqual(got, []interface{}{"x"}) {
+		t.Errorf("list holds %v after a rejected push, want [x]", got)
+	}
+}
+
+func TestImportChecksCapacity(t *testing.T) {
+	c := New(0, 0, WithMaxCost(10), WithWeigher(DefaultWeigher))
+	if err := c.Set("a", "1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Import(map[string]Item{"big": {Value: "far too large to fit"}}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("big"); ok {
+		t.Error("entry heavier than the cache was imported")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("importing an oversized entry evicted a")
+	}
+}
@@ -0,0 +1,62 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"time"
+)
+
+// SetWithTags stores value like Set and associates it with tags, so that
+// it can later be removed together with other entries via InvalidateTag.
+func (c *Cache) SetWithTags(key string, value interface{}, duration time.Duration, tags ...string) error {
+	item := c.newItem(key, value, duration, false)
+	if len(tags) > 0 {
+		item.Tags = append([]string(nil), tags...)
+	}
+	return c.setItem(key, item, setAlways)
+}
+
+// InvalidateTag removes every entry tagged with tag and returns how many
+// were removed.
+func (c *Cache) InvalidateTag(tag string) int {
+	if c.writable() != nil {
+		return 0
+	}
+
+	var removed []keyedItem
+	for _, s := range c.shards {
+		s.Lock()
+		for key := range s.tags[tag] {
+			// Expired entries still held are left for GC.
+			if _, ok := s.live(key); !ok || c.storeDelete(context.Background(), key) != nil {
+				continue
+			}
+			if item, ok := s.remove(key); ok {
+				removed = append(removed, keyedItem{key: key, item: item})
+			}
+		}
+		s.Unlock()
+	}
+
+	c.deleted(removed)
+	c.invalidate(keysOf(removed)...)
+	return len(removed)
+}
+
+// KeysWithTag returns the keys of live entries tagged with tag.
+func (c *Cache) KeysWithTag(tag string) []string {
+	if c.closed.Load() {
+		return nil
+	}
+
+	var keys []string
+	for _, s := range c.shards {
+		s.RLock()
+		for key := range s.tags[tag] {
+			if _, ok := s.live(key); ok {
+				keys = append(keys, key)
+			}
+		}
+		s.RUnlock()
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+package go_in_memory_cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBulkDeletesSkipExpired(t *testing.T) {
+	tests := []struct {
+		name string
+		del  func(c *Cache) int
+	}{
+		{name: "InvalidateTag", del: func(c *Cache) int { return c.InvalidateTag("t") }},
+		{name: "DeleteByPrefix", del: func(c *Cache) int { return c.DeleteByPrefix("k") }},
+		{name: "DeleteMatching", del: func(c *Cache) int { return c.DeleteMatching("k*") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(epoch)
+			store := &recordingStore{}
+			c := New(0, 0, WithClock(clock), WithWriteThrough(store))
+			if err := c.SetWithTags("k1", 1, time.Minute, "t"); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.SetWithTags("k2", 2, time.Hour, "t"); err != nil {
+				t.Fatal(err)
+			}
+			clock.Advance(2 * time.Minute)
+			store.take()
+
+			if n := tt.del(c); n != 1 {
+				t.Errorf("removed %d entries, want 1", n)
+			}
+			if got := c.Stats().Deletes; got != 1 {
+				t.Errorf("Stats().Deletes = %d, want 1", got)
+			}
+			if ops := store.take(); len(ops) != 1 || ops[0] != "delete k2" {
+				t.Errorf("store saw %q, want [delete k2]", ops)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package go_in_memory_cache
+
+import (
+	"errors"
+	"time"
+)
+
+// TierWritePolicy decides how a TieredCache applies writes to its levels.
+type TierWritePolicy int
+
+const (
+	// WriteBoth writes L2 first and then L1.
+	WriteBoth TierWritePolicy = iota
+	// WriteL2Invalidate writes L2 and drops the key from L1, leaving L1 to
+	// be back-filled on the next read.
+	WriteL2Invalidate
+)
+
+// TieredCache is a two-level cache: reads are served from L1 when possible
+// and otherwise from L2, back-filling L1. It implements CacheInterface, so
+// levels can themselves be tiered.
+type TieredCache struct {
+	l1, l2 CacheInterface
+	policy TierWritePolicy
+	l1TTL  time.Duration
+}
+
+type TieredOption func(*TieredCache)
+
+// WithTierWritePolicy selects how writes reach the levels. The default is
+// WriteBoth.
+func WithTierWritePolicy(p TierWritePolicy) TieredOption {
+	return func(t *TieredCache) {
+		t.policy = p
+	}
+}
+
+// WithL1TTL caps the lifetime of entries written to L1, so that a small
+// fast L1 does not hold values much longer than it takes L2 to change.
+func WithL1TTL(d time.Duration) TieredOption {
+	return func(t *TieredCache) {
+		t.l1TTL = d
+	}
+}
+
+// Tiered layers l1 in front of l2, for example an in-process Cache in front
+// of a shared Redis-backed implementation of CacheInterface.
+func Tiered(l1, l2 CacheInterface, opts ...TieredOption) *TieredCache {
+	t := &TieredCache{l1: l1, l2: l2}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *TieredCache) Set(key string, value interface{}, duration time.Duration) error {
+	if err := t.l2.Set(key, value, duration); err != nil {
+		return err
+	}
+	if t.policy == WriteL2Invalidate {
+		t.l1.Delete(key)
+		return nil
+	}
+	return t.l1.Set(key, value, t.l1Lifetime(duration))
+}
+
+func (t *TieredCache) Get(key string) (interface{}, bool) {
+	if value, ok := t.l1.Get(key); ok {
+		return value, true
+	}
+	item, ok := t.l2GetItem(key)
+	if !ok {
+		return nil, false
+	}
+	return item.Value, true
+}
+
+func (t *TieredCache) GetItem(key string) (*Item, bool) {
+	if item, ok := t.l1.GetItem(key); ok {
+		return item, true
+	}
+	return t.l2GetItem(key)
+}
+
+// l2GetItem reads key from L2 and back-fills L1 with the entry's remaining
+// lifetime.
+func (t *TieredCache) l2GetItem(key string) (*Item, bool) {
+	item, ok := t.l2.GetItem(key)
+	if !ok {
+		return nil, false
+	}
+
+	duration := NoExpiration
+	if item.Expired > 0 {
+		duration = time.Duration(item.Expired - t.l2Now().UnixNano())
+		if duration <= 0 {
+			return item, true
+		}
+	}
+	t.l1.Set(key, item.Value, t.l1Lifetime(duration))
+	return item, true
+}
+
+// l2Now is the current time by L2's clock, which its expiry times are
+// measured against.
+func (t *TieredCache) l2Now() time.Time {
+	if c, ok := t.l2.(interface{ Clock() Clock }); ok {
+		return c.Clock().Now()
+	}
+	return time.Now()
+}
+
+// l1Lifetime applies WithL1TTL to a lifetime meant for L2.
+func (t *TieredCache) l1Lifetime(duration time.Duration) time.Duration {
+	if t.l1TTL <= 0 {
+		return duration
+	}
+	if duration <= 0 || duration > t.l1TTL {
+		return t.l1TTL
+	}
+	return duration
+}
+
+// Delete removes key from both levels. It fails with ErrKeyNotFound only
+// if neither held it.
+func (t *TieredCache) Delete(key string) error {
+	err2 := t.l2.Delete(key)
+	if err2 != nil && !errors.Is(err2, ErrKeyNotFound) {
+		return err2
+	}
+	err1 := t.l1.Delete(key)
+	if err1 != nil && !errors.Is(err1, ErrKeyNotFound) {
+		return err1
+	}
+	if err1 != nil && err2 != nil {
+		return err2
+	}
+	return nil
+}
+
+// Count reports the number of entries in L2, which holds every entry L1
+// does.
+func (t *TieredCache) Count() int {
+	return t.l2.Count()
+}
+
+// Rename renames key in L2 and drops both keys from L1.
+func (t *TieredCache) Rename(key, newKey string) error {
+	if err := t.l2.Rename(key, newKey); err != nil {
+		return err
+	}
+	t.l1.Delete(key)
+	t.l1.Delete(newKey)
+	return nil
+}
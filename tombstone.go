@@ -0,0 +1,59 @@
+package go_in_memory_cache
+
+import "time"
+
+// Tombstone records why and when an entry disappeared, for caches created
+// WithTombstones. Item is the removed entry without its Value.
+type Tombstone struct {
+	Reason  EventType
+	Removed time.Time
+	Item    Item
+}
+
+// GetTombstone returns the tombstone left by the last removal of key, if
+// it is within the retention period and nothing was stored under key
+// since. Flush and Close leave no tombstones.
+func (c *Cache) GetTombstone(key string) (Tombstone, bool) {
+	s := c.shardFor(key)
+	s.RLock()
+	defer s.RUnlock()
+
+	t, ok := s.tombstones[key]
+	if !ok || c.clock.Now().Sub(t.Removed) > time.Duration(s.tombstoneRetention) {
+		return Tombstone{}, false
+	}
+	return t, true
+}
+
+// bury leaves a tombstone for key, removed as t. The caller must hold the
+// write lock.
+func (s *shard) bury(key string, item Item, t EventType) {
+	if s.tombstoneRetention <= 0 {
+		return
+	}
+	if s.tombstones == nil {
+		s.tombstones = make(map[string]Tombstone)
+	}
+	item.Value = nil
+	s.tombstones[key] = Tombstone{Reason: t, Removed: s.clock.Now(), Item: item}
+}
+
+// purgeRemoved drops tombstones and undo entries whose time is up.
+func (s *shard) purgeRemoved() {
+	if s.undoWindow == 0 && s.tombstoneRetention <= 0 {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	now := s.clock.Now().UnixNano()
+	for key, u := range s.undo {
+		if now > u.until {
+			delete(s.undo, key)
+		}
+	}
+	for key, t := range s.tombstones {
+		if now-t.Removed.UnixNano() > s.tombstoneRetention {
+			delete(s.tombstones, key)
+		}
+	}
+}
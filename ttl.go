@@ -0,0 +1,150 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// NoExpiration is reported by TTL for entries that never expire. Passing it
+// to Set stores an entry without an expiry regardless of the default
+// lifetime.
+const NoExpiration time.Duration = -1
+
+// KeepTTL can be passed to Update to keep the existing entry's expiry. A
+// key created this way does not expire.
+const KeepTTL time.Duration = -2
+
+// DefaultLifetime returns the lifetime given to entries stored with a
+// duration of 0. A value <= 0 means such entries never expire.
+func (c *Cache) DefaultLifetime() time.Duration {
+	return c.defaultLifetime
+}
+
+// ttlRule gives keys matching pattern their own default lifetime.
+type ttlRule struct {
+	pattern  string
+	lifetime time.Duration
+}
+
+// LifetimeFor returns the lifetime given to key when it is stored with a
+// duration of 0: that of the first WithTTLRule matching key, or the
+// default lifetime.
+func (c *Cache) LifetimeFor(key string) time.Duration {
+	for _, r := range c.ttlRules {
+		if matchGlob(r.pattern, key) {
+			return r.lifetime
+		}
+	}
+	return c.defaultLifetime
+}
+
+// Touch resets the lifetime of key to duration from now, using
+// LifetimeFor(key) when duration is 0.
+func (c *Cache) Touch(key string, duration time.Duration) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+
+	item, ok := s.live(key)
+	if !ok {
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+
+	item.Expired = c.expiration(key, duration)
+	if err := c.storePut(context.Background(), key, item); err != nil {
+		s.Unlock()
+		return err
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return nil
+}
+
+// TTL returns the remaining lifetime of key, or NoExpiration if it never
+// expires. It does not count as an access, so a sliding entry's lifetime is
+// not restarted.
+func (c *Cache) TTL(key string) (time.Duration, bool) {
+	item, ok := c.peek(key)
+	if !ok {
+		return 0, false
+	}
+	if item.Expired == 0 {
+		return NoExpiration, true
+	}
+	return time.Unix(0, item.Expired).Sub(c.clock.Now()), true
+}
+
+// SetWithDeadline stores value so that it expires at t. A zero t stores it
+// without expiry.
+func (c *Cache) SetWithDeadline(key string, value interface{}, t time.Time) error {
+	item := c.newItem(key, value, NoExpiration, false)
+	item.Expired = deadline(t)
+	return c.setItem(key, item, setAlways)
+}
+
+// ExpireAt makes key expire at t, replacing any relative or sliding
+// lifetime. A zero t removes the expiry.
+func (c *Cache) ExpireAt(key string, t time.Time) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+
+	item, ok := s.live(key)
+	if !ok {
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+
+	item.Expired = deadline(t)
+	item.Sliding = 0
+	if err := c.storePut(context.Background(), key, item); err != nil {
+		s.Unlock()
+		return err
+	}
+	evicted := s.store(key, item)
+	s.Unlock()
+
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return nil
+}
+
+// ExpiringWithin returns the keys that will expire within d from now,
+// soonest first. Sliding entries are included based on their current
+// deadline.
+func (c *Cache) ExpiringWithin(d time.Duration) []string {
+	if c.closed.Load() {
+		return nil
+	}
+
+	now := c.clock.Now().UnixNano()
+	var found []*expiryEntry
+	for _, s := range c.shards {
+		found = append(found, s.expiringBy(now, now+int64(d))...)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].at < found[j].at })
+
+	keys := make([]string, len(found))
+	for i, e := range found {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+func deadline(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
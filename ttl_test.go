@@ -0,0 +1,26 @@
+package go_in_memory_cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLDoesNotSlide(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	c := New(0, 0, WithClock(clock))
+	if err := c.SetSliding("key", "value", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(40 * time.Second)
+	if d, ok := c.TTL("key"); !ok || d != 20*time.Second {
+		t.Fatalf("TTL = %v, %v; want 20s, true", d, ok)
+	}
+	if hits := c.Stats().Hits; hits != 0 {
+		t.Errorf("TTL counted %d hits", hits)
+	}
+	clock.Advance(21 * time.Second)
+	if _, ok := c.Get("key"); ok {
+		t.Error("TTL kept a sliding entry alive")
+	}
+}
@@ -0,0 +1,163 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"time"
+)
+
+// Txn buffers the reads and writes of a transaction started with Tx. It is
+// not safe for concurrent use and must not be used after Tx returns.
+type Txn struct {
+	c      *Cache
+	reads  map[string]txnRead
+	writes map[string]txnWrite
+	order  []string
+}
+
+type txnRead struct {
+	version uint64
+	ok      bool
+}
+
+type txnWrite struct {
+	item    Item
+	deleted bool
+}
+
+// Tx runs fn and then applies the writes it made through tx atomically:
+// either all become visible together or none do. If fn returns an error
+// nothing is applied. If any key tx read or wrote was changed by someone
+// else before commit, Tx fails with ErrTxConflict and the caller may
+// retry.
+func (c *Cache) Tx(fn func(tx *Txn) error) error {
+	if c.closed.Load() {
+		return ErrCacheClosed
+	}
+
+	tx := &Txn{
+		c:      c,
+		reads:  make(map[string]txnRead),
+		writes: make(map[string]txnWrite),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.commit()
+}
+
+// Get returns the value of key as seen by the transaction, including its
+// own uncommitted writes.
+func (tx *Txn) Get(key string) (interface{}, bool) {
+	if w, ok := tx.writes[key]; ok {
+		if w.deleted {
+			return nil, false
+		}
+		return tx.c.valueOf(w.item), true
+	}
+
+	item, ok := tx.read(key)
+	if !ok || item.Negative {
+		return nil, false
+	}
+	return tx.c.valueOf(item), true
+}
+
+// Set buffers storing value under key until commit.
+func (tx *Txn) Set(key string, value interface{}, duration time.Duration) {
+	tx.read(key)
+	tx.write(key, txnWrite{item: tx.c.newItem(key, value, duration, false)})
+}
+
+// Delete buffers removing key until commit. Deleting a key that holds no
+// entry at commit is not an error.
+func (tx *Txn) Delete(key string) {
+	tx.read(key)
+	tx.write(key, txnWrite{deleted: true})
+}
+
+// read returns the live entry for key and, on first use, remembers the
+// version it saw for conflict detection at commit.
+func (tx *Txn) read(key string) (Item, bool) {
+	s := tx.c.shardFor(key)
+	s.RLock()
+	item, ok := s.live(key)
+	s.RUnlock()
+
+	if _, seen := tx.reads[key]; !seen {
+		tx.reads[key] = txnRead{version: item.Version, ok: ok}
+	}
+	return item, ok
+}
+
+func (tx *Txn) write(key string, w txnWrite) {
+	if _, ok := tx.writes[key]; !ok {
+		tx.order = append(tx.order, key)
+	}
+	tx.writes[key] = w
+}
+
+func (tx *Txn) commit() error {
+	if len(tx.writes) == 0 {
+		return nil
+	}
+	c := tx.c
+
+	keys := make([]string, 0, len(tx.reads))
+	for key := range tx.reads {
+		keys = append(keys, key)
+	}
+	unlock := c.lockKeys(keys...)
+
+	if err := c.writable(); err != nil {
+		unlock()
+		return err
+	}
+	for key, r := range tx.reads {
+		item, ok := c.shardFor(key).live(key)
+		if ok != r.ok || (ok && item.Version != r.version) {
+			unlock()
+			return keyError(ErrTxConflict, key)
+		}
+	}
+	for _, key := range tx.order {
+		w := tx.writes[key]
+		if s := c.shardFor(key); !w.deleted && s.maxCost > 0 && s.weigh(key, w.item.Value) > s.maxCost {
+			unlock()
+			return keyError(ErrCapacityExceeded, key)
+		}
+	}
+	for _, key := range tx.order {
+		var err error
+		if tx.writes[key].deleted {
+			err = c.storeDelete(context.Background(), key)
+		} else {
+			err = c.storePut(context.Background(), key, tx.writes[key].item)
+		}
+		if err != nil {
+			unlock()
+			return err
+		}
+	}
+
+	var evicted, removed []keyedItem
+	var changed []string
+	for _, key := range tx.order {
+		s, w := c.shardFor(key), tx.writes[key]
+		if w.deleted {
+			if item, ok := s.remove(key); ok {
+				removed = append(removed, keyedItem{key: key, item: item})
+				changed = append(changed, key)
+			}
+			continue
+		}
+		evicted = append(evicted, s.store(key, w.item)...)
+		changed = append(changed, key)
+		c.stats.sets.Add(1)
+	}
+	unlock()
+
+	c.deleted(removed)
+	c.overflowed(evicted)
+	c.invalidate(changed...)
+	return nil
+}
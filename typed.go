@@ -0,0 +1,103 @@
+package go_in_memory_cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Typed is a Cache with keys of type K and values of type V. Entries live
+// in an underlying Cache, so options, sharding, expiry, GC and Close behave
+// as they do there. A string key is stored as is; any other key is stored
+// under its %#v form, so keys that format alike share an entry.
+type Typed[K comparable, V any] struct {
+	cache *Cache
+}
+
+type TypedItem[V any] struct {
+	Value   V
+	Created time.Time
+	Expired int64
+}
+
+func NewTyped[K comparable, V any](defaultLifetime, cleanupInterval time.Duration, opts ...Option) *Typed[K, V] {
+	return &Typed[K, V]{cache: New(defaultLifetime, cleanupInterval, opts...)}
+}
+
+func typedKey[K comparable](key K) string {
+	if s, ok := any(key).(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%#v", key)
+}
+
+// Set stores value under key, replacing any existing entry.
+func (c *Typed[K, V]) Set(key K, value V, duration time.Duration) error {
+	return c.cache.Set(typedKey(key), value, duration)
+}
+
+// Add stores value only if key does not hold a live entry.
+func (c *Typed[K, V]) Add(key K, value V, duration time.Duration) error {
+	return c.cache.Add(typedKey(key), value, duration)
+}
+
+// Replace stores value only if key already holds a live entry.
+func (c *Typed[K, V]) Replace(key K, value V, duration time.Duration) error {
+	return c.cache.Replace(typedKey(key), value, duration)
+}
+
+func (c *Typed[K, V]) Get(key K) (V, bool) {
+	item, ok := c.GetItem(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	return item.Value, true
+}
+
+func (c *Typed[K, V]) GetItem(key K) (*TypedItem[V], bool) {
+	item, ok := c.cache.GetItem(typedKey(key))
+	if !ok {
+		return nil, false
+	}
+
+	value, _ := item.Value.(V)
+	return &TypedItem[V]{Value: value, Created: item.Created, Expired: item.Expired}, true
+}
+
+func (c *Typed[K, V]) Delete(key K) error {
+	return c.cache.Delete(typedKey(key))
+}
+
+// StartGC starts the cleanup goroutine, as Cache.StartGC.
+func (c *Typed[K, V]) StartGC() {
+	c.cache.StartGC()
+}
+
+// GC removes expired entries until the cache is closed, as Cache.GC.
+func (c *Typed[K, V]) GC() {
+	c.cache.GC()
+}
+
+func (c *Typed[K, V]) ClearItems(keys []K) {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = typedKey(key)
+	}
+	c.cache.ClearItems(names)
+}
+
+// Count returns the number of live entries.
+func (c *Typed[K, V]) Count() int {
+	return c.cache.Count()
+}
+
+func (c *Typed[K, V]) Rename(key, newKey K) error {
+	return c.cache.Rename(typedKey(key), typedKey(newKey))
+}
+
+// Close stops the cleanup goroutine and releases all entries. Set on a
+// closed cache fails with ErrCacheClosed.
+func (c *Typed[K, V]) Close() error {
+	return c.cache.Close()
+}
@@ -0,0 +1,54 @@
+package go_in_memory_cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type point struct{ X, Y int }
+
+func TestTyped(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	c := NewTyped[point, int](0, 0, WithClock(clock))
+	defer c.Close()
+	c.StartGC()
+
+	if err := c.Set(point{1, 2}, 12, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(point{2, 1}, 21, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(point{1, 2}, 0, 0); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("Add over a live key = %v, want %v", err, ErrKeyExists)
+	}
+	if v, ok := c.Get(point{1, 2}); !ok || v != 12 {
+		t.Errorf("Get = %v, %v; want 12, true", v, ok)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if _, ok := c.Get(point{1, 2}); ok {
+		t.Error("expired entry still readable")
+	}
+	if n := c.Count(); n != 1 {
+		t.Errorf("Count = %d, want 1", n)
+	}
+	if err := c.Delete(point{1, 2}); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Delete of an expired key = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if err := c.Rename(point{2, 1}, point{3, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c.Get(point{3, 3}); !ok || v != 21 {
+		t.Errorf("Get after Rename = %v, %v; want 21, true", v, ok)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(point{1, 1}, 1, 0); !errors.Is(err, ErrCacheClosed) {
+		t.Errorf("Set after Close = %v, want %v", err, ErrCacheClosed)
+	}
+}
@@ -0,0 +1,126 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"time"
+)
+
+// pendingDelete is what DeleteAfter replaced on an entry: its own expiry,
+// restored by UndoDelete.
+type pendingDelete struct {
+	expired int64
+	sliding time.Duration
+}
+
+// undoEntry keeps an entry removed by DeleteAfter until the undo window
+// closes at until.
+type undoEntry struct {
+	item  Item
+	until int64
+}
+
+// DeleteAfter schedules key for removal in d. The entry stays readable
+// until then; UndoDelete cancels the removal, and, within the window set
+// by WithUndoWindow, brings the entry back afterwards. Writing or deleting
+// key in the meantime discards the schedule. Removal happens as GC or lazy
+// expiry reaches the entry, so it is reported to watchers as a delete and
+// to OnEvicted when it is actually removed.
+func (c *Cache) DeleteAfter(key string, d time.Duration) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+
+	item, ok := s.live(key)
+	if !ok {
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+	pending, ok := s.doomed[key]
+	if !ok {
+		pending = pendingDelete{expired: item.Expired, sliding: item.Sliding}
+	}
+	at := c.clock.Now().Add(d).UnixNano()
+	if item.Expired == 0 || at < item.Expired || ok {
+		item.Expired = at
+	}
+	item.Sliding = 0
+	if err := c.storePut(context.Background(), key, item); err != nil {
+		s.Unlock()
+		return err
+	}
+	evicted := s.store(key, item)
+	if s.doomed == nil {
+		s.doomed = make(map[string]pendingDelete)
+	}
+	s.doomed[key] = pending
+	s.Unlock()
+
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return nil
+}
+
+// UndoDelete cancels a DeleteAfter for key, restoring the entry's own
+// expiry. Once the entry has been removed it can still be restored until
+// the WithUndoWindow period ends, provided nothing was stored under key
+// since. It fails with ErrKeyNotFound otherwise.
+func (c *Cache) UndoDelete(key string) error {
+	if err := c.writable(); err != nil {
+		return err
+	}
+
+	s := c.shardFor(key)
+	s.Lock()
+	if pending, ok := s.doomed[key]; ok {
+		item := s.items[key]
+		item.Expired, item.Sliding = pending.expired, pending.sliding
+		if err := c.storePut(context.Background(), key, item); err != nil {
+			s.Unlock()
+			return err
+		}
+		evicted := s.store(key, item)
+		s.Unlock()
+
+		c.overflowed(evicted)
+		c.invalidate(key)
+		return nil
+	}
+	t, ok := s.undo[key]
+	if !ok || c.clock.Now().UnixNano() > t.until {
+		s.Unlock()
+		return keyError(ErrKeyNotFound, key)
+	}
+	if err := c.storePut(context.Background(), key, t.item); err != nil {
+		s.Unlock()
+		return err
+	}
+	evicted := s.store(key, t.item)
+	s.Unlock()
+
+	c.overflowed(evicted)
+	c.invalidate(key)
+	return nil
+}
+
+// expire removes key once its deadline has passed. An entry scheduled by
+// DeleteAfter is removed as a delete and kept for the undo window. The
+// caller must hold the write lock.
+func (s *shard) expire(key string) (Item, bool) {
+	pending, ok := s.doomed[key]
+	if !ok {
+		return s.removeAs(key, EventExpire)
+	}
+	item, ok := s.removeAs(key, EventDelete)
+	if ok && s.undoWindow > 0 {
+		if s.undo == nil {
+			s.undo = make(map[string]undoEntry)
+		}
+		restored := item
+		restored.Expired, restored.Sliding = pending.expired, pending.sliding
+		s.undo[key] = undoEntry{item: restored, until: s.clock.Now().UnixNano() + s.undoWindow}
+	}
+	return item, ok
+}
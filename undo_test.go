@@ -0,0 +1,58 @@
+package go_in_memory_cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRewritesReportEvictions grows an entry in place with each operation
+// so that storing it evicts the other entry, which must reach OnEvicted.
+func TestRewritesReportEvictions(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(c *Cache) error
+	}{
+		{name: "Increment", op: func(c *Cache) error {
+			_, err := c.Increment("grow", 1)
+			return err
+		}},
+		{name: "Touch", op: func(c *Cache) error { return c.Touch("grow", time.Minute) }},
+		{name: "ExpireAt", op: func(c *Cache) error { return c.ExpireAt("grow", epoch.Add(time.Minute)) }},
+		{name: "DeleteAfter", op: func(c *Cache) error { return c.DeleteAfter("grow", time.Minute) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var evicted []string
+			weight := int64(1)
+			c := New(0, 0,
+				WithClock(NewFakeClock(epoch)),
+				WithMaxCost(3),
+				WithWeigher(func(key string, value interface{}) int64 {
+					if key == "grow" {
+						return weight
+					}
+					return 1
+				}),
+				WithOnEvicted(func(key string, value interface{}) { evicted = append(evicted, key) }),
+			)
+			if err := c.Set("other", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Set("grow", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			weight = 3
+			if err := tt.op(c); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(evicted, []string{"other"}) {
+				t.Errorf("OnEvicted saw %q, want [other]", evicted)
+			}
+			if n := c.Stats().Evictions; n != 1 {
+				t.Errorf("Evictions = %d, want 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package go_in_memory_cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WarmEntry is an entry for Warm to store, as if by Set.
+type WarmEntry struct {
+	Key   string
+	Value interface{}
+	TTL   time.Duration
+}
+
+// WarmSource produces the entries Warm stores. Next returns io.EOF once
+// there are no more. With WarmConcurrency above 1, Next is called from
+// several goroutines at once.
+type WarmSource interface {
+	Next(ctx context.Context) (WarmEntry, error)
+}
+
+// WarmSourceFunc adapts a function to WarmSource.
+type WarmSourceFunc func(ctx context.Context) (WarmEntry, error)
+
+func (f WarmSourceFunc) Next(ctx context.Context) (WarmEntry, error) {
+	return f(ctx)
+}
+
+// WarmChannel returns a WarmSource yielding the entries received from ch
+// until it is closed.
+func WarmChannel(ch <-chan WarmEntry) WarmSource {
+	return WarmSourceFunc(func(ctx context.Context) (WarmEntry, error) {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return WarmEntry{}, io.EOF
+			}
+			return e, nil
+		case <-ctx.Done():
+			return WarmEntry{}, ctx.Err()
+		}
+	})
+}
+
+// WarmKeys returns a WarmSource loading each of keys with l. With
+// WarmConcurrency above 1 the loads run in parallel.
+func WarmKeys(keys []string, l Loader) WarmSource {
+	var next atomic.Int64
+	return WarmSourceFunc(func(ctx context.Context) (WarmEntry, error) {
+		i := next.Add(1) - 1
+		if i >= int64(len(keys)) {
+			return WarmEntry{}, io.EOF
+		}
+		value, ttl, err := l.Load(ctx, keys[i])
+		if err != nil {
+			return WarmEntry{}, keyError(err, keys[i])
+		}
+		return WarmEntry{Key: keys[i], Value: value, TTL: ttl}, nil
+	})
+}
+
+// WarmFile returns a WarmSource yielding the live entries of a snapshot
+// written by SaveFile, decoded with the settings of the cache being warmed.
+// Entries keep their remaining lifetime.
+func WarmFile(path string) WarmSource {
+	return &fileSource{path: path}
+}
+
+type fileSource struct {
+	path string
+	c    *Cache
+
+	once    sync.Once
+	mu      sync.Mutex
+	err     error
+	entries []WarmEntry
+}
+
+func (f *fileSource) bind(c *Cache) {
+	f.c = c
+}
+
+func (f *fileSource) Next(ctx context.Context) (WarmEntry, error) {
+	f.once.Do(f.read)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return WarmEntry{}, f.err
+	}
+	if len(f.entries) == 0 {
+		return WarmEntry{}, io.EOF
+	}
+	e := f.entries[0]
+	f.entries = f.entries[1:]
+	return e, nil
+}
+
+func (f *fileSource) read() {
+	if f.c == nil {
+		f.err = errors.New("WarmFile source used outside Warm")
+		return
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		f.err = err
+		return
+	}
+	items, err := f.c.decodeSnapshot(data)
+	if err != nil {
+		f.err = err
+		return
+	}
+	now := f.c.clock.Now().UnixNano()
+	for key, item := range items {
+		ttl := NoExpiration
+		if item.Expired > 0 {
+			if now > item.Expired {
+				continue
+			}
+			ttl = time.Duration(item.Expired - now)
+		}
+		f.entries = append(f.entries, WarmEntry{Key: key, Value: item.Value, TTL: ttl})
+	}
+}
+
+// WarmOption configures a Warm call.
+type WarmOption func(*warmOptions)
+
+type warmOptions struct {
+	concurrency int
+	every       int
+	progress    func(loaded int)
+}
+
+// WarmConcurrency stores entries from n goroutines at once. The default is
+// 1.
+func WarmConcurrency(n int) WarmOption {
+	return func(o *warmOptions) {
+		o.concurrency = max(n, 1)
+	}
+}
+
+// WarmProgress calls fn with the number of entries stored so far after
+// every that many entries, and with the total when Warm finishes. Calls do
+// not overlap.
+func WarmProgress(every int, fn func(loaded int)) WarmOption {
+	return func(o *warmOptions) {
+		o.every = max(every, 1)
+		o.progress = fn
+	}
+}
+
+// Warm stores the entries of src and returns how many it stored. It stops
+// at the first error from src, other than io.EOF, or when ctx is done. If
+// the cache was created WithWarmup, a Warm that completes without error
+// marks it ready.
+func (c *Cache) Warm(ctx context.Context, src WarmSource, opts ...WarmOption) (int, error) {
+	if err := c.writable(); err != nil {
+		return 0, err
+	}
+	o := warmOptions{concurrency: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if b, ok := src.(interface{ bind(*Cache) }); ok {
+		b.bind(c)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		loaded     int
+		progressMu sync.Mutex
+		errOnce    sync.Once
+		firstErr   error
+		wg         sync.WaitGroup
+	)
+	fail := func(err error) {
+		errOnce.Do(func() { firstErr = err })
+		cancel()
+	}
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				e, err := src.Next(ctx)
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err == nil {
+					err = c.Set(e.Key, e.Value, e.TTL)
+				}
+				if err != nil {
+					fail(err)
+					return
+				}
+				progressMu.Lock()
+				loaded++
+				if o.progress != nil && loaded%o.every == 0 {
+					o.progress(loaded)
+				}
+				progressMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if o.progress != nil && (loaded == 0 || loaded%o.every != 0) {
+		o.progress(loaded)
+	}
+	if firstErr == nil {
+		firstErr = parent.Err()
+	}
+	if firstErr == nil {
+		c.readyOnce.Do(func() { close(c.ready) })
+	}
+	return loaded, firstErr
+}
+
+// Ready returns a channel closed once the cache is ready to serve: right
+// away, or for caches created WithWarmup, after the first successful Warm.
+func (c *Cache) Ready() <-chan struct{} {
+	return c.ready
+}
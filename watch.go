@@ -0,0 +1,182 @@
+package go_in_memory_cache
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type EventType int
+
+const (
+	EventSet EventType = iota + 1
+	EventDelete
+	EventExpire
+	EventEvict
+)
+
+// EventMask selects event types in Subscribe.
+type EventMask uint
+
+const (
+	MaskSet    EventMask = 1 << EventSet
+	MaskDelete EventMask = 1 << EventDelete
+	MaskExpire EventMask = 1 << EventExpire
+	MaskEvict  EventMask = 1 << EventEvict
+	MaskAll              = MaskSet | MaskDelete | MaskExpire | MaskEvict
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	case EventEvict:
+		return "evict"
+	}
+	return "unknown"
+}
+
+// Event describes a change to a key. Value is the new value for EventSet and
+// the removed value otherwise.
+type Event struct {
+	Type  EventType
+	Key   string
+	Value interface{}
+}
+
+// watchBuffer is the number of events a watcher may fall behind by before
+// further events are dropped.
+const watchBuffer = 64
+
+type watcher struct {
+	key    string
+	prefix bool
+	all    bool
+	mask   EventMask
+	ch     chan Event
+}
+
+type watchers struct {
+	mu     sync.RWMutex
+	next   int
+	subs   map[int]*watcher
+	count  atomic.Int32
+	closed bool
+	value  func(Item) interface{}
+
+	changes atomic.Pointer[changeLog]
+}
+
+// Watch returns a channel receiving every change to key and a function that
+// stops the watch and closes the channel. Events are delivered without
+// blocking the cache; if the receiver falls behind, events are dropped.
+func (c *Cache) Watch(key string) (<-chan Event, func()) {
+	return c.watch.add(&watcher{key: key, mask: MaskAll})
+}
+
+// WatchPrefix is like Watch but reports changes to every key starting with
+// prefix.
+func (c *Cache) WatchPrefix(prefix string) (<-chan Event, func()) {
+	return c.watch.add(&watcher{key: prefix, prefix: true, mask: MaskAll})
+}
+
+// Subscribe returns a channel receiving every event whose type is in mask,
+// for any key, and a function that cancels the subscription. Delivery is as
+// in Watch.
+func (c *Cache) Subscribe(mask EventMask) (<-chan Event, func()) {
+	return c.watch.add(&watcher{all: true, mask: mask})
+}
+
+func (w *watchers) add(sub *watcher) (<-chan Event, func()) {
+	sub.ch = make(chan Event, watchBuffer)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	if w.subs == nil {
+		w.subs = make(map[int]*watcher)
+	}
+	id := w.next
+	w.next++
+	w.subs[id] = sub
+	w.count.Add(1)
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() { w.remove(id) })
+	}
+}
+
+func (w *watchers) remove(id int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sub, ok := w.subs[id]; ok {
+		delete(w.subs, id)
+		w.count.Add(-1)
+		close(sub.ch)
+	}
+}
+
+// notify delivers an event to every matching watcher. It is called with the
+// shard lock held, so it never blocks.
+func (w *watchers) notify(t EventType, key string, item Item) {
+	if w == nil {
+		return
+	}
+	log := w.changes.Load()
+	if log == nil && w.count.Load() == 0 {
+		return
+	}
+
+	var value interface{}
+	decoded := false
+	if log != nil {
+		value, decoded = w.value(item), true
+		log.append(Event{Type: t, Key: key, Value: value})
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for _, sub := range w.subs {
+		if sub.mask&(1<<t) == 0 || !sub.matches(key) {
+			continue
+		}
+		if !decoded {
+			value, decoded = w.value(item), true
+		}
+		select {
+		case sub.ch <- Event{Type: t, Key: key, Value: value}:
+		default:
+		}
+	}
+}
+
+func (w *watcher) matches(key string) bool {
+	if w.all || w.key == key {
+		return true
+	}
+	return w.prefix && strings.HasPrefix(key, w.key)
+}
+
+// close closes every watcher channel; later watches get a closed channel.
+func (w *watchers) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if log := w.changes.Load(); log != nil {
+		log.close()
+	}
+	for id, sub := range w.subs {
+		delete(w.subs, id)
+		close(sub.ch)
+	}
+	w.count.Store(0)
+}
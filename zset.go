@@ -0,0 +1,376 @@
+package go_in_memory_cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"math/rand/v2"
+	"sync"
+)
+
+func init() {
+	gob.Register([]ZMember(nil))
+}
+
+// ZMember is a member of a sorted set with its score.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+const zMaxLevel = 32
+
+// zNode is a skip list node. span counts the level-0 steps each link skips,
+// which is what makes ranks cheap to compute.
+type zNode struct {
+	member string
+	score  float64
+	next   []zLink
+}
+
+type zLink struct {
+	node *zNode
+	span int
+}
+
+// zsetValue is the container behind the sorted set operations: a skip list
+// ordered by score, then member, plus each member's score. Its plain form
+// is []ZMember in that order.
+type zsetValue struct {
+	sync.RWMutex
+	head   *zNode
+	level  int
+	scores map[string]float64
+	bytes  int64
+}
+
+func newZset() *zsetValue {
+	return &zsetValue{
+		head:   &zNode{next: make([]zLink, zMaxLevel)},
+		level:  1,
+		scores: make(map[string]float64),
+	}
+}
+
+var zsetType = containerType[*zsetValue]{
+	name: "sorted set",
+	make: newZset,
+	adopt: func(v interface{}) (*zsetValue, bool) {
+		switch v := v.(type) {
+		case *zsetValue:
+			return v, true
+		case []ZMember:
+			z := newZset()
+			for _, m := range v {
+				z.add(m.Member, m.Score)
+			}
+			return z, true
+		}
+		return nil, false
+	},
+}
+
+// before reports whether n sorts before the member with the given score.
+func (n *zNode) before(score float64, member string) bool {
+	return n.score < score || n.score == score && n.member < member
+}
+
+func zRandomLevel() int {
+	level := 1
+	for level < zMaxLevel && rand.Uint32()&3 == 0 {
+		level++
+	}
+	return level
+}
+
+// add sets member's score and reports whether member is new.
+func (z *zsetValue) add(member string, score float64) bool {
+	old, ok := z.scores[member]
+	if ok {
+		if old == score {
+			return false
+		}
+		z.unlink(member, old)
+	} else {
+		z.bytes += int64(len(member)) + 8
+	}
+	z.scores[member] = score
+	z.link(member, score)
+	return !ok
+}
+
+func (z *zsetValue) rem(member string) bool {
+	score, ok := z.scores[member]
+	if !ok {
+		return false
+	}
+	z.unlink(member, score)
+	delete(z.scores, member)
+	z.bytes -= int64(len(member)) + 8
+	return true
+}
+
+func (z *zsetValue) link(member string, score float64) {
+	var update [zMaxLevel]*zNode
+	var rank [zMaxLevel]int
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		if i < z.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i].node != nil && x.next[i].node.before(score, member) {
+			rank[i] += x.next[i].span
+			x = x.next[i].node
+		}
+		update[i] = x
+	}
+
+	level := zRandomLevel()
+	if level > z.level {
+		for i := z.level; i < level; i++ {
+			update[i] = z.head
+			z.head.next[i].span = len(z.scores) - 1
+		}
+		z.level = level
+	}
+	n := &zNode{member: member, score: score, next: make([]zLink, level)}
+	for i := 0; i < level; i++ {
+		n.next[i].node = update[i].next[i].node
+		update[i].next[i].node = n
+		n.next[i].span = update[i].next[i].span - (rank[0] - rank[i])
+		update[i].next[i].span = rank[0] - rank[i] + 1
+	}
+	for i := level; i < z.level; i++ {
+		update[i].next[i].span++
+	}
+}
+
+func (z *zsetValue) unlink(member string, score float64) {
+	var update [zMaxLevel]*zNode
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && x.next[i].node.before(score, member) {
+			x = x.next[i].node
+		}
+		update[i] = x
+	}
+	x = x.next[0].node
+	for i := 0; i < z.level; i++ {
+		if update[i].next[i].node == x {
+			update[i].next[i].span += x.next[i].span - 1
+			update[i].next[i].node = x.next[i].node
+		} else {
+			update[i].next[i].span--
+		}
+	}
+	for z.level > 1 && z.head.next[z.level-1].node == nil {
+		z.level--
+	}
+}
+
+// rank returns the 0-based position of member, which must be present.
+func (z *zsetValue) rank(member string) int {
+	score := z.scores[member]
+	rank := 0
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && (x.next[i].node.before(score, member) || x.next[i].node.member == member) {
+			rank += x.next[i].span
+			x = x.next[i].node
+		}
+		if x != z.head && x.member == member {
+			return rank - 1
+		}
+	}
+	return -1
+}
+
+// at returns the node at 0-based position r, which must be in range.
+func (z *zsetValue) at(r int) *zNode {
+	traversed := 0
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && traversed+x.next[i].span <= r+1 {
+			traversed += x.next[i].span
+			x = x.next[i].node
+		}
+		if traversed == r+1 {
+			return x
+		}
+	}
+	return nil
+}
+
+// rangeByRank returns members start through stop, inclusive, with negative
+// positions counting from the end.
+func (z *zsetValue) rangeByRank(start, stop int) []ZMember {
+	n := len(z.scores)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	stop = min(stop, n-1)
+	if start > stop {
+		return nil
+	}
+	out := make([]ZMember, 0, stop-start+1)
+	for x := z.at(start); len(out) < cap(out); x = x.next[0].node {
+		out = append(out, ZMember{Member: x.member, Score: x.score})
+	}
+	return out
+}
+
+// rangeByScore returns the members scoring from min through max.
+func (z *zsetValue) rangeByScore(min, max float64) []ZMember {
+	x := z.head
+	for i := z.level - 1; i >= 0; i-- {
+		for x.next[i].node != nil && x.next[i].node.score < min {
+			x = x.next[i].node
+		}
+	}
+	var out []ZMember
+	for x = x.next[0].node; x != nil && x.score <= max; x = x.next[0].node {
+		out = append(out, ZMember{Member: x.member, Score: x.score})
+	}
+	return out
+}
+
+func (z *zsetValue) view() interface{} {
+	z.RLock()
+	defer z.RUnlock()
+	return z.rangeByRank(0, -1)
+}
+
+func (z *zsetValue) clone() container {
+	z.RLock()
+	defer z.RUnlock()
+	c := newZset()
+	for x := z.head.next[0].node; x != nil; x = x.next[0].node {
+		c.add(x.member, x.score)
+	}
+	return c
+}
+
+func (*zsetValue) kind() string {
+	return "sorted set"
+}
+
+func (z *zsetValue) len() int {
+	return len(z.scores)
+}
+
+func (z *zsetValue) weight() int64 {
+	return z.bytes
+}
+
+// ZAdd adds members to the sorted set under key, or updates the scores of
+// those already in it, and returns how many were new. A missing key is
+// created as a sorted set with the default lifetime; an existing one keeps
+// its expiry. A key holding a []ZMember is treated as a sorted set. Any
+// other value fails with ErrTypeMismatch.
+func (c *Cache) ZAdd(key string, members ...ZMember) (int, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	var n int
+	err := withContainer(c, key, zsetType, true, func(z *zsetValue) (bool, error) {
+		changed := false
+		for _, m := range members {
+			if old, ok := z.scores[m.Member]; ok && old == m.Score {
+				continue
+			}
+			if z.add(m.Member, m.Score) {
+				n++
+			}
+			changed = true
+		}
+		return changed, nil
+	})
+	return n, err
+}
+
+// ZRem removes members from the sorted set under key and returns how many
+// were in it. A sorted set left empty is deleted.
+func (c *Cache) ZRem(key string, members ...string) (int, error) {
+	var n int
+	err := withContainer(c, key, zsetType, false, func(z *zsetValue) (bool, error) {
+		for _, member := range members {
+			if z.rem(member) {
+				n++
+			}
+		}
+		return n > 0, nil
+	})
+	if errors.Is(err, ErrKeyNotFound) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// ZRange returns the members of the sorted set under key ranked start
+// through stop, inclusive, lowest score first. Negative ranks count from
+// the end, so ZRange(key, 0, -1) returns every member.
+func (c *Cache) ZRange(key string, start, stop int) ([]ZMember, error) {
+	var out []ZMember
+	_, err := readContainer(c, key, zsetType, func(z *zsetValue) {
+		out = z.rangeByRank(start, stop)
+	})
+	return out, err
+}
+
+// ZRangeByScore returns the members of the sorted set under key scoring
+// from min through max, lowest score first.
+func (c *Cache) ZRangeByScore(key string, min, max float64) ([]ZMember, error) {
+	var out []ZMember
+	_, err := readContainer(c, key, zsetType, func(z *zsetValue) {
+		out = z.rangeByScore(min, max)
+	})
+	return out, err
+}
+
+// ZRank returns the 0-based rank of member in the sorted set under key,
+// lowest score first. It fails with ErrKeyNotFound if the key or the
+// member is missing.
+func (c *Cache) ZRank(key, member string) (int, error) {
+	rank := -1
+	_, err := readContainer(c, key, zsetType, func(z *zsetValue) {
+		if _, ok := z.scores[member]; ok {
+			rank = z.rank(member)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	if rank < 0 {
+		return 0, keyError(ErrKeyNotFound, key)
+	}
+	return rank, nil
+}
+
+// ZScore returns the score of member in the sorted set under key. It fails
+// with ErrKeyNotFound if the key or the member is missing.
+func (c *Cache) ZScore(key, member string) (float64, error) {
+	var score float64
+	var ok bool
+	_, err := readContainer(c, key, zsetType, func(z *zsetValue) {
+		score, ok = z.scores[member]
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, keyError(ErrKeyNotFound, key)
+	}
+	return score, nil
+}
+
+// ZCard returns the number of members in the sorted set under key, 0 if it
+// is missing.
+func (c *Cache) ZCard(key string) (int, error) {
+	var n int
+	_, err := readContainer(c, key, zsetType, func(z *zsetValue) {
+		n = len(z.scores)
+	})
+	return n, err
+}